@@ -0,0 +1,109 @@
+// Package assignee provides functionality for choosing issue assignees.
+// It includes assignee pools that distribute issues across a list of users, and
+// resolution of team mentions and CODEOWNERS entries into user logins.
+package assignee
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Supported assignee pool strategies
+const (
+	StrategyRoundRobin = "round-robin"
+	StrategyRandom     = "random"
+	StrategyLeastOpen  = "least-open"
+)
+
+// OpenIssueCounter counts the open issues assigned to a user in a repository
+type OpenIssueCounter interface {
+	CountOpenAssignedIssues(repo string, user string) (int, error)
+}
+
+// Pool picks one assignee per issue from a list of users
+type Pool struct {
+	strategy string
+	users    []string
+	counter  OpenIssueCounter
+
+	next int
+	rng  *rand.Rand
+	// load holds open issue counts per repository and user, including
+	// issues assigned earlier in this run
+	load map[string]map[string]int
+}
+
+// NewPool creates a new assignee pool. The seed is only used by the random
+// strategy; a zero seed picks a time-based one. The counter is only used by
+// the least-open strategy.
+func NewPool(strategy string, users []string, seed int64, counter OpenIssueCounter) (*Pool, error) {
+	if len(users) == 0 {
+		return nil, fmt.Errorf("assignee pool has no users")
+	}
+
+	pool := &Pool{strategy: strategy, users: users, counter: counter}
+	switch strategy {
+	case StrategyRoundRobin:
+	case StrategyRandom:
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		pool.rng = rand.New(rand.NewSource(seed))
+	case StrategyLeastOpen:
+		if counter == nil {
+			return nil, fmt.Errorf("strategy '%s' requires a GitHub client", strategy)
+		}
+		pool.load = make(map[string]map[string]int)
+	default:
+		return nil, fmt.Errorf("unknown assignee pool strategy '%s': must be %s, %s or %s",
+			strategy, StrategyRoundRobin, StrategyRandom, StrategyLeastOpen)
+	}
+
+	return pool, nil
+}
+
+// Strategy returns the strategy of the pool
+func (p *Pool) Strategy() string {
+	return p.strategy
+}
+
+// Next picks the assignee for the next issue in repo
+func (p *Pool) Next(repo string) (string, error) {
+	switch p.strategy {
+	case StrategyRandom:
+		return p.users[p.rng.Intn(len(p.users))], nil
+	case StrategyLeastOpen:
+		return p.nextLeastOpen(repo)
+	default:
+		user := p.users[p.next%len(p.users)]
+		p.next++
+		return user, nil
+	}
+}
+
+// nextLeastOpen picks the user with the fewest open assigned issues in repo,
+// preferring earlier users on ties
+func (p *Pool) nextLeastOpen(repo string) (string, error) {
+	load, ok := p.load[repo]
+	if !ok {
+		load = make(map[string]int, len(p.users))
+		for _, user := range p.users {
+			count, err := p.counter.CountOpenAssignedIssues(repo, user)
+			if err != nil {
+				return "", fmt.Errorf("failed to count open issues for %s in %s: %v", user, repo, err)
+			}
+			load[user] = count
+		}
+		p.load[repo] = load
+	}
+
+	chosen := p.users[0]
+	for _, user := range p.users[1:] {
+		if load[user] < load[chosen] {
+			chosen = user
+		}
+	}
+	load[chosen]++
+	return chosen, nil
+}
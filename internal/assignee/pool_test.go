@@ -0,0 +1,117 @@
+package assignee
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// MockCounter provides fixed open issue counts for testing
+type MockCounter struct {
+	Counts map[string]int
+	Calls  int
+	Err    error
+}
+
+// CountOpenAssignedIssues implements the OpenIssueCounter interface for testing
+func (m *MockCounter) CountOpenAssignedIssues(repo string, user string) (int, error) {
+	m.Calls++
+	if m.Err != nil {
+		return 0, m.Err
+	}
+	return m.Counts[repo+"/"+user], nil
+}
+
+// pick returns the next n assignees for repo
+func pick(t *testing.T, pool *Pool, repo string, n int) []string {
+	t.Helper()
+	var picked []string
+	for i := 0; i < n; i++ {
+		user, err := pool.Next(repo)
+		if err != nil {
+			t.Fatalf("Next failed: %v", err)
+		}
+		picked = append(picked, user)
+	}
+	return picked
+}
+
+func TestRoundRobin(t *testing.T) {
+	pool, err := NewPool(StrategyRoundRobin, []string{"alice", "bob", "carol"}, 0, nil)
+	if err != nil {
+		t.Fatalf("NewPool failed: %v", err)
+	}
+
+	expected := []string{"alice", "bob", "carol", "alice"}
+	if picked := pick(t, pool, "octo/repo", 4); !reflect.DeepEqual(picked, expected) {
+		t.Errorf("Expected %v, got %v", expected, picked)
+	}
+}
+
+func TestRandomWithSeed(t *testing.T) {
+	users := []string{"alice", "bob", "carol"}
+
+	first, _ := NewPool(StrategyRandom, users, 42, nil)
+	second, _ := NewPool(StrategyRandom, users, 42, nil)
+
+	firstPicks := pick(t, first, "octo/repo", 10)
+	secondPicks := pick(t, second, "octo/repo", 10)
+	if !reflect.DeepEqual(firstPicks, secondPicks) {
+		t.Errorf("Expected the same seed to give the same picks, got %v and %v", firstPicks, secondPicks)
+	}
+
+	for _, user := range firstPicks {
+		if user != "alice" && user != "bob" && user != "carol" {
+			t.Errorf("Unexpected assignee '%s'", user)
+		}
+	}
+}
+
+func TestLeastOpen(t *testing.T) {
+	counter := &MockCounter{Counts: map[string]int{
+		"octo/repo/alice": 3,
+		"octo/repo/bob":   1,
+		"octo/repo/carol": 2,
+	}}
+
+	pool, err := NewPool(StrategyLeastOpen, []string{"alice", "bob", "carol"}, 0, counter)
+	if err != nil {
+		t.Fatalf("NewPool failed: %v", err)
+	}
+
+	// Counts grow as issues are assigned during the run; ties go to the earlier user
+	expected := []string{"bob", "bob", "carol", "alice", "bob"}
+	if picked := pick(t, pool, "octo/repo", 5); !reflect.DeepEqual(picked, expected) {
+		t.Errorf("Expected %v, got %v", expected, picked)
+	}
+
+	// Counts are fetched once per repository and user
+	if counter.Calls != 3 {
+		t.Errorf("Expected 3 count lookups, got %d", counter.Calls)
+	}
+
+	// Another repository has its own counts
+	if user, _ := pool.Next("octo/other"); user != "alice" {
+		t.Errorf("Expected 'alice' for a repository without open issues, got '%s'", user)
+	}
+}
+
+func TestLeastOpenCounterError(t *testing.T) {
+	pool, _ := NewPool(StrategyLeastOpen, []string{"alice"}, 0, &MockCounter{Err: errors.New("boom")})
+
+	if _, err := pool.Next("octo/repo"); err == nil {
+		t.Error("Expected error from counter, got nil")
+	}
+}
+
+func TestNewPoolErrors(t *testing.T) {
+	if _, err := NewPool(StrategyRoundRobin, nil, 0, nil); err == nil {
+		t.Error("Expected error for empty pool, got nil")
+	}
+	if _, err := NewPool("weighted", []string{"alice"}, 0, nil); err == nil {
+		t.Error("Expected error for unknown strategy, got nil")
+	}
+	if _, err := NewPool(StrategyLeastOpen, []string{"alice"}, 0, nil); err == nil {
+		t.Error("Expected error for least-open without counter, got nil")
+	}
+}
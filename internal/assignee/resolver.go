@@ -0,0 +1,183 @@
+package assignee
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ntsk/gh-issue-bulk-create/internal/codeowners"
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+// MaxAssignees is the maximum number of assignees GitHub accepts on an issue
+const MaxAssignees = 10
+
+// Directory looks up team members and CODEOWNERS files on GitHub. An empty
+// host means the default host.
+type Directory interface {
+	ListTeamMembers(host string, org string, team string) ([]string, error)
+	GetCodeowners(repo string) (string, error)
+}
+
+// Assignment is a resolved assignee together with where it came from
+type Assignment struct {
+	Login  string
+	Source string
+}
+
+// Resolver expands team mentions and CODEOWNERS entries into user logins.
+// Team members and CODEOWNERS files are cached for the lifetime of the resolver.
+type Resolver struct {
+	directory  Directory
+	teams      map[string][]string
+	codeowners map[string]*codeowners.File
+}
+
+// NewResolver creates a new assignee resolver
+func NewResolver(directory Directory) *Resolver {
+	return &Resolver{
+		directory:  directory,
+		teams:      make(map[string][]string),
+		codeowners: make(map[string]*codeowners.File),
+	}
+}
+
+// NeedsResolution reports whether assignees or a CODEOWNERS path require lookups
+func NeedsResolution(assignees []string, codeownersPath string) bool {
+	if codeownersPath != "" {
+		return true
+	}
+	for _, assignee := range assignees {
+		if strings.Contains(assignee, "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve expands assignees (logins or @org/team mentions) and the owners of
+// codeownersPath in repo's CODEOWNERS file into user logins. Duplicates are
+// dropped and the result is capped at MaxAssignees; anything skipped is
+// reported as a warning.
+func (r *Resolver) Resolve(repo string, assignees []string, codeownersPath string) ([]Assignment, []string, error) {
+	var assignments []Assignment
+	var warnings []string
+	seen := make(map[string]bool)
+
+	// Teams are looked up on the same host as the repository
+	ref, _ := models.ParseRepoRef(repo)
+
+	add := func(login, source string) {
+		key := strings.ToLower(login)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		assignments = append(assignments, Assignment{Login: login, Source: source})
+	}
+
+	addOwner := func(owner, source string) error {
+		owner = strings.TrimPrefix(owner, "@")
+		if org, team, ok := strings.Cut(owner, "/"); ok {
+			members, err := r.teamMembers(ref.Host, org, team)
+			if err != nil {
+				return err
+			}
+			if len(members) == 0 {
+				warnings = append(warnings, fmt.Sprintf("team @%s has no members", owner))
+			}
+			for _, member := range members {
+				add(member, source+" via team @"+owner)
+			}
+			return nil
+		}
+		add(owner, source)
+		return nil
+	}
+
+	for _, assignee := range assignees {
+		if strings.TrimSpace(assignee) == "" {
+			continue
+		}
+		if err := addOwner(strings.TrimSpace(assignee), "assignees"); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if codeownersPath != "" {
+		file, err := r.codeownersFile(repo)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var rule *codeowners.Rule
+		if file != nil {
+			rule = file.Match(codeownersPath)
+		}
+		switch {
+		case file == nil:
+			warnings = append(warnings, fmt.Sprintf("no CODEOWNERS file found in %s", repo))
+		case rule == nil || len(rule.Owners) == 0:
+			warnings = append(warnings, fmt.Sprintf("no CODEOWNERS owners for '%s' in %s", codeownersPath, repo))
+		default:
+			for _, owner := range rule.Owners {
+				// Owners given by email address cannot be assigned
+				if !strings.HasPrefix(owner, "@") {
+					warnings = append(warnings, fmt.Sprintf("skipped CODEOWNERS owner '%s' (not a user or team)", owner))
+					continue
+				}
+				if err := addOwner(owner, fmt.Sprintf("CODEOWNERS %s", rule.Pattern)); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+	}
+
+	if len(assignments) > MaxAssignees {
+		var dropped []string
+		for _, assignment := range assignments[MaxAssignees:] {
+			dropped = append(dropped, assignment.Login)
+		}
+		warnings = append(warnings, fmt.Sprintf("only %d assignees are allowed, dropped: %s", MaxAssignees, strings.Join(dropped, ", ")))
+		assignments = assignments[:MaxAssignees]
+	}
+
+	return assignments, warnings, nil
+}
+
+// teamMembers returns the members of an organization team, cached per host and team
+func (r *Resolver) teamMembers(host, org, team string) ([]string, error) {
+	key := strings.ToLower(host + "/" + org + "/" + team)
+	if members, ok := r.teams[key]; ok {
+		return members, nil
+	}
+
+	members, err := r.directory.ListTeamMembers(host, org, team)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list members of team @%s/%s: %v", org, team, err)
+	}
+	r.teams[key] = members
+	return members, nil
+}
+
+// codeownersFile returns the parsed CODEOWNERS file of repo, or nil if the
+// repository has none, cached per repository
+func (r *Resolver) codeownersFile(repo string) (*codeowners.File, error) {
+	if file, ok := r.codeowners[repo]; ok {
+		return file, nil
+	}
+
+	content, err := r.directory.GetCodeowners(repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CODEOWNERS for %s: %v", repo, err)
+	}
+
+	var file *codeowners.File
+	if content != "" {
+		file, err = codeowners.Parse(content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CODEOWNERS for %s: %v", repo, err)
+		}
+	}
+	r.codeowners[repo] = file
+	return file, nil
+}
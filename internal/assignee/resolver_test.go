@@ -0,0 +1,173 @@
+package assignee
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// MockDirectory provides fixed teams and CODEOWNERS files for testing
+type MockDirectory struct {
+	Teams           map[string][]string
+	Codeowners      map[string]string
+	TeamCalls       int
+	CodeownersCalls int
+}
+
+// ListTeamMembers implements the Directory interface for testing
+func (m *MockDirectory) ListTeamMembers(host string, org string, team string) ([]string, error) {
+	m.TeamCalls++
+	key := org + "/" + team
+	if host != "" {
+		key = host + "/" + key
+	}
+	members, ok := m.Teams[key]
+	if !ok {
+		return nil, fmt.Errorf("team not found")
+	}
+	return members, nil
+}
+
+// GetCodeowners implements the Directory interface for testing
+func (m *MockDirectory) GetCodeowners(repo string) (string, error) {
+	m.CodeownersCalls++
+	return m.Codeowners[repo], nil
+}
+
+func TestResolve(t *testing.T) {
+	directory := &MockDirectory{
+		Teams: map[string][]string{
+			"octo/payments": {"alice", "bob"},
+			"octo/docs":     {"carol"},
+
+			"ghe.example.com/octo/payments": {"frank"},
+		},
+		Codeowners: map[string]string{
+			"octo/api": "*  @octo/docs\n/payments/ @octo/payments @dave ops@example.com\n",
+		},
+	}
+	resolver := NewResolver(directory)
+
+	testCases := []struct {
+		name             string
+		repo             string
+		assignees        []string
+		path             string
+		expected         []Assignment
+		expectedWarnings []string
+		expectedError    bool
+	}{
+		{
+			name:      "Logins and team mentions",
+			repo:      "octo/api",
+			assignees: []string{"erin", "@octo/payments", "alice"},
+			expected: []Assignment{
+				{Login: "erin", Source: "assignees"},
+				{Login: "alice", Source: "assignees via team @octo/payments"},
+				{Login: "bob", Source: "assignees via team @octo/payments"},
+			},
+		},
+		{
+			name: "CODEOWNERS owners by path",
+			repo: "octo/api",
+			path: "payments/refund.go",
+			expected: []Assignment{
+				{Login: "alice", Source: "CODEOWNERS /payments/ via team @octo/payments"},
+				{Login: "bob", Source: "CODEOWNERS /payments/ via team @octo/payments"},
+				{Login: "dave", Source: "CODEOWNERS /payments/"},
+			},
+			expectedWarnings: []string{"skipped CODEOWNERS owner 'ops@example.com'"},
+		},
+		{
+			name:             "Repository without CODEOWNERS",
+			repo:             "octo/web",
+			path:             "src/main.go",
+			expected:         nil,
+			expectedWarnings: []string{"no CODEOWNERS file found in octo/web"},
+		},
+		{
+			name:      "Team on the repository's host",
+			repo:      "ghe.example.com/octo/api",
+			assignees: []string{"@octo/payments"},
+			expected: []Assignment{
+				{Login: "frank", Source: "assignees via team @octo/payments"},
+			},
+		},
+		{
+			name:          "Unknown team",
+			repo:          "octo/api",
+			assignees:     []string{"@octo/unknown"},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assignments, warnings, err := resolver.Resolve(tc.repo, tc.assignees, tc.path)
+
+			if tc.expectedError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if !reflect.DeepEqual(assignments, tc.expected) {
+				t.Errorf("Expected assignments %v, got %v", tc.expected, assignments)
+			}
+
+			if len(warnings) != len(tc.expectedWarnings) {
+				t.Fatalf("Expected warnings %v, got %v", tc.expectedWarnings, warnings)
+			}
+			for i, expected := range tc.expectedWarnings {
+				if !strings.Contains(warnings[i], expected) {
+					t.Errorf("Expected warning containing '%s', got '%s'", expected, warnings[i])
+				}
+			}
+		})
+	}
+
+	// Teams and CODEOWNERS files are looked up once per run
+	if directory.CodeownersCalls != 2 {
+		t.Errorf("Expected 2 CODEOWNERS lookups, got %d", directory.CodeownersCalls)
+	}
+	if directory.TeamCalls != 3 {
+		t.Errorf("Expected 3 team lookups, got %d", directory.TeamCalls)
+	}
+}
+
+func TestResolveCapsAssignees(t *testing.T) {
+	var members []string
+	for i := 1; i <= 12; i++ {
+		members = append(members, fmt.Sprintf("user%d", i))
+	}
+	resolver := NewResolver(&MockDirectory{Teams: map[string][]string{"octo/large": members}})
+
+	assignments, warnings, err := resolver.Resolve("octo/api", []string{"@octo/large"}, "")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(assignments) != MaxAssignees {
+		t.Errorf("Expected %d assignees, got %d", MaxAssignees, len(assignments))
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "user11, user12") {
+		t.Errorf("Expected a warning about dropped assignees, got %v", warnings)
+	}
+}
+
+func TestNeedsResolution(t *testing.T) {
+	if NeedsResolution([]string{"alice", "bob"}, "") {
+		t.Error("Expected plain logins not to need resolution")
+	}
+	if !NeedsResolution([]string{"@octo/payments"}, "") {
+		t.Error("Expected team mentions to need resolution")
+	}
+	if !NeedsResolution(nil, "src/main.go") {
+		t.Error("Expected a CODEOWNERS path to need resolution")
+	}
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// GitHub rejects app JWTs that expire more than 10 minutes after they were issued
+	jwtLifetime = 9 * time.Minute
+	// Issue JWTs slightly in the past to allow for clock drift
+	jwtClockSkew = time.Minute
+	// Refresh installation tokens this long before they expire
+	refreshBefore = 5 * time.Minute
+)
+
+// AppTokenSource provides GitHub App installation tokens, exchanging a JWT
+// signed with the app's private key for a new token before the current one
+// expires
+type AppTokenSource struct {
+	appID          int64
+	installationID int64
+	key            *rsa.PrivateKey
+	apiURL         string
+	httpClient     *http.Client
+	now            func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewAppTokenSource creates a token source for a GitHub App on a host. If
+// installationID is 0, the app must have exactly one installation.
+func NewAppTokenSource(host string, appID int64, installationID int64, privateKey []byte) (*AppTokenSource, error) {
+	if appID <= 0 {
+		return nil, fmt.Errorf("invalid GitHub App ID %d", appID)
+	}
+	key, err := ParsePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AppTokenSource{
+		appID:          appID,
+		installationID: installationID,
+		key:            key,
+		apiURL:         APIURL(host),
+		httpClient:     &http.Client{Timeout: 30 * time.Second},
+		now:            time.Now,
+	}, nil
+}
+
+// ParsePrivateKey parses a PEM encoded RSA private key in PKCS#1 or PKCS#8 form,
+// as downloaded from the GitHub App settings
+func ParsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("private key is not PEM encoded")
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %v", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// SignJWT creates the RS256 signed JWT a GitHub App uses to authenticate as itself
+func SignJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-jwtClockSkew).Unix(),
+		"exp": now.Add(jwtLifetime).Unix(),
+		"iss": fmt.Sprintf("%d", appID),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	encoding := base64.RawURLEncoding
+	unsigned := encoding.EncodeToString(header) + "." + encoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign JWT: %v", err)
+	}
+
+	return unsigned + "." + encoding.EncodeToString(signature), nil
+}
+
+// Token returns the current installation token, creating a new one if there is
+// none yet or it is about to expire
+func (s *AppTokenSource) Token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if s.token != "" && now.Add(refreshBefore).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	jwt, err := SignJWT(s.appID, s.key, now)
+	if err != nil {
+		return "", err
+	}
+
+	if s.installationID == 0 {
+		id, err := s.findInstallation(jwt)
+		if err != nil {
+			return "", err
+		}
+		s.installationID = id
+	}
+
+	var response struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	path := fmt.Sprintf("app/installations/%d/access_tokens", s.installationID)
+	if err := s.request(http.MethodPost, path, jwt, &response); err != nil {
+		return "", fmt.Errorf("failed to create installation token: %v", err)
+	}
+	if response.Token == "" {
+		return "", fmt.Errorf("failed to create installation token: empty response")
+	}
+
+	s.token = response.Token
+	s.expiresAt = response.ExpiresAt
+	return s.token, nil
+}
+
+// findInstallation returns the ID of the app's only installation
+func (s *AppTokenSource) findInstallation(jwt string) (int64, error) {
+	var installations []struct {
+		ID      int64 `json:"id"`
+		Account struct {
+			Login string `json:"login"`
+		} `json:"account"`
+	}
+	if err := s.request(http.MethodGet, "app/installations?per_page=100", jwt, &installations); err != nil {
+		return 0, fmt.Errorf("failed to list GitHub App installations: %v", err)
+	}
+
+	switch len(installations) {
+	case 0:
+		return 0, fmt.Errorf("GitHub App %d is not installed on any account", s.appID)
+	case 1:
+		return installations[0].ID, nil
+	default:
+		var accounts []string
+		for _, installation := range installations {
+			accounts = append(accounts, fmt.Sprintf("%s (%d)", installation.Account.Login, installation.ID))
+		}
+		return 0, fmt.Errorf("GitHub App %d has several installations, specify one of: %s", s.appID, strings.Join(accounts, ", "))
+	}
+}
+
+// request sends a request authenticated as the app and decodes the JSON response
+func (s *AppTokenSource) request(method string, path string, jwt string, response interface{}) error {
+	req, err := http.NewRequest(method, s.apiURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return json.Unmarshal(body, response)
+}
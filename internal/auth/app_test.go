@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testKey generates an RSA key and its PKCS#1 PEM encoding
+func testKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	return key, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func TestSignJWT(t *testing.T) {
+	key, _ := testKey(t)
+	now := time.Unix(1700000000, 0)
+
+	token, err := SignJWT(12345, key, now)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		t.Fatalf("Expected 3 JWT segments, got %d", len(parts))
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatalf("Failed to decode signature: %v", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Errorf("Expected a valid RS256 signature, got: %v", err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("Failed to decode claims: %v", err)
+	}
+	var claims struct {
+		IAT int64  `json:"iat"`
+		EXP int64  `json:"exp"`
+		ISS string `json:"iss"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		t.Fatalf("Failed to parse claims: %v", err)
+	}
+	if claims.ISS != "12345" {
+		t.Errorf("Expected issuer '12345', got '%s'", claims.ISS)
+	}
+	if claims.IAT != now.Unix()-60 || claims.EXP != now.Unix()+540 {
+		t.Errorf("Expected iat %d and exp %d, got %d and %d", now.Unix()-60, now.Unix()+540, claims.IAT, claims.EXP)
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key, pkcs1 := testKey(t)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+	pkcs8 := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	for name, data := range map[string][]byte{"PKCS1": pkcs1, "PKCS8": pkcs8} {
+		parsed, err := ParsePrivateKey(data)
+		if err != nil {
+			t.Errorf("%s: expected no error, got: %v", name, err)
+			continue
+		}
+		if !parsed.Equal(key) {
+			t.Errorf("%s: parsed key does not match", name)
+		}
+	}
+
+	if _, err := ParsePrivateKey([]byte("not a key")); err == nil {
+		t.Error("Expected error for non-PEM data, got nil")
+	}
+}
+
+func TestAppTokenSource(t *testing.T) {
+	_, pemData := testKey(t)
+	now := time.Unix(1700000000, 0)
+
+	var tokenRequests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/app/installations":
+			fmt.Fprint(w, `[{"id": 42, "account": {"login": "octo"}}]`)
+		case "/app/installations/42/access_tokens":
+			tokenRequests++
+			expiresAt := now.Add(time.Hour).UTC().Format(time.RFC3339)
+			fmt.Fprintf(w, `{"token": "ghs_%d", "expires_at": "%s"}`, tokenRequests, expiresAt)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	source, err := NewAppTokenSource("github.com", 12345, 0, pemData)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	source.apiURL = server.URL + "/"
+	source.now = func() time.Time { return now }
+
+	token, err := source.Token()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if token != "ghs_1" {
+		t.Errorf("Expected token 'ghs_1', got '%s'", token)
+	}
+
+	// The token is reused while it is valid for long enough
+	now = now.Add(30 * time.Minute)
+	if token, _ := source.Token(); token != "ghs_1" {
+		t.Errorf("Expected cached token 'ghs_1', got '%s'", token)
+	}
+
+	// and refreshed shortly before it expires
+	now = now.Add(26 * time.Minute)
+	if token, _ := source.Token(); token != "ghs_2" {
+		t.Errorf("Expected refreshed token 'ghs_2', got '%s'", token)
+	}
+}
+
+func TestAppTokenSourceSeveralInstallations(t *testing.T) {
+	_, pemData := testKey(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[{"id": 1, "account": {"login": "octo"}}, {"id": 2, "account": {"login": "acme"}}]`)
+	}))
+	defer server.Close()
+
+	source, err := NewAppTokenSource("github.com", 12345, 0, pemData)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	source.apiURL = server.URL + "/"
+
+	_, err = source.Token()
+	if err == nil || !strings.Contains(err.Error(), "acme (2)") {
+		t.Errorf("Expected error listing installations, got: %v", err)
+	}
+}
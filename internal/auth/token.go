@@ -0,0 +1,75 @@
+// Package auth provides token sources for authenticating to the GitHub API
+// without a user's gh login, such as a token from an environment variable or
+// a GitHub App installation.
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	ghauth "github.com/cli/go-gh/v2/pkg/auth"
+)
+
+// TokenSource provides tokens for GitHub API requests
+type TokenSource interface {
+	Token() (string, error)
+}
+
+// StaticToken is a token that never changes
+type StaticToken string
+
+// Token returns the token
+func (t StaticToken) Token() (string, error) {
+	if t == "" {
+		return "", fmt.Errorf("token is empty")
+	}
+	return string(t), nil
+}
+
+// EnvToken creates a token source from the token in an environment variable
+func EnvToken(name string) (TokenSource, error) {
+	token := strings.TrimSpace(os.Getenv(name))
+	if token == "" {
+		return nil, fmt.Errorf("environment variable %s is not set", name)
+	}
+	return StaticToken(token), nil
+}
+
+// Transport sets the Authorization header of every request from a token
+// source, so that tokens refreshed during a run are picked up
+type Transport struct {
+	Source TokenSource
+	Base   http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.Source.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "token "+token)
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// APIURL returns the REST API base URL of a host. An empty host uses the host
+// configured for gh (GH_HOST or github.com).
+func APIURL(host string) string {
+	if host == "" {
+		host, _ = ghauth.DefaultHost()
+	}
+	host = ghauth.NormalizeHostname(host)
+	if ghauth.IsEnterprise(host) && !ghauth.IsTenancy(host) {
+		return fmt.Sprintf("https://%s/api/v3/", host)
+	}
+	return fmt.Sprintf("https://api.%s/", host)
+}
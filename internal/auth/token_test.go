@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEnvToken(t *testing.T) {
+	t.Setenv("BULK_CREATE_TOKEN", "ghp_secret")
+
+	source, err := EnvToken("BULK_CREATE_TOKEN")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if token, _ := source.Token(); token != "ghp_secret" {
+		t.Errorf("Expected token 'ghp_secret', got '%s'", token)
+	}
+
+	if _, err := EnvToken("BULK_CREATE_MISSING_TOKEN"); err == nil {
+		t.Error("Expected error for unset variable, got nil")
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &Transport{Source: StaticToken("ghs_fresh")}}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req.Header.Set("Authorization", "token stale")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	resp.Body.Close()
+
+	if authorization != "token ghs_fresh" {
+		t.Errorf("Expected 'token ghs_fresh', got '%s'", authorization)
+	}
+	if req.Header.Get("Authorization") != "token stale" {
+		t.Error("Expected the original request to be left unchanged")
+	}
+}
+
+func TestAPIURL(t *testing.T) {
+	testCases := map[string]string{
+		"github.com":      "https://api.github.com/",
+		"ghe.example.com": "https://ghe.example.com/api/v3/",
+		"octo.ghe.com":    "https://api.octo.ghe.com/",
+	}
+
+	for host, expected := range testCases {
+		if url := APIURL(host); url != expected {
+			t.Errorf("APIURL(%s): expected '%s', got '%s'", host, expected, url)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+// Package codeowners provides functionality for parsing CODEOWNERS files.
+// It includes matching file paths against CODEOWNERS rules to find their owners.
+package codeowners
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Locations lists where GitHub looks for a CODEOWNERS file, in order
+var Locations = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// Rule is a single CODEOWNERS line: a path pattern and its owners
+type Rule struct {
+	Pattern string
+	Owners  []string
+	re      *regexp.Regexp
+}
+
+// File is a parsed CODEOWNERS file
+type File struct {
+	Rules []Rule
+}
+
+// Parse parses the content of a CODEOWNERS file
+func Parse(content string) (*File, error) {
+	file := &File{}
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// Drop trailing comments
+		if index := strings.Index(line, " #"); index >= 0 {
+			line = strings.TrimSpace(line[:index])
+		}
+
+		fields := strings.Fields(line)
+		re, err := patternToRegexp(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid CODEOWNERS pattern '%s' on line %d: %v", fields[0], lineNumber, err)
+		}
+		file.Rules = append(file.Rules, Rule{Pattern: fields[0], Owners: fields[1:], re: re})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Match returns the last rule matching path, or nil. As on GitHub, a later
+// rule takes precedence, and a matching rule without owners clears ownership.
+func (f *File) Match(path string) *Rule {
+	path = strings.TrimPrefix(strings.TrimSpace(path), "/")
+	for i := len(f.Rules) - 1; i >= 0; i-- {
+		if f.Rules[i].re.MatchString(path) {
+			return &f.Rules[i]
+		}
+	}
+	return nil
+}
+
+// patternToRegexp converts a gitignore-style CODEOWNERS pattern into a regular
+// expression matching a repository-relative path and, for directories,
+// anything below it
+func patternToRegexp(pattern string) (*regexp.Regexp, error) {
+	// Patterns with a slash at the start or in the middle are relative to the
+	// repository root; others match at any depth
+	trimmed := strings.TrimSuffix(pattern, "/")
+	anchored := strings.Contains(trimmed, "/")
+	trimmed = strings.TrimPrefix(trimmed, "/")
+
+	// A pattern names a directory whose contents it owns when it ends in a
+	// slash or its last segment is a plain name: "docs/" and "docs" match
+	// docs/a/b.md, but "docs/*" only matches the files directly in docs
+	lastSegment := trimmed[strings.LastIndex(trimmed, "/")+1:]
+	directory := strings.HasSuffix(pattern, "/") || !strings.ContainsAny(lastSegment, "*?")
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(trimmed); i++ {
+		c := trimmed[i]
+		switch {
+		case c == '*' && strings.HasPrefix(trimmed[i:], "**/"):
+			expr.WriteString("(?:.*/)?")
+			i += 2
+		case c == '*' && strings.HasPrefix(trimmed[i:], "**"):
+			expr.WriteString(".*")
+			i++
+		case c == '*':
+			expr.WriteString("[^/]*")
+		case c == '?':
+			expr.WriteString("[^/]")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	if directory {
+		expr.WriteString("(?:/.*)?")
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
@@ -0,0 +1,97 @@
+package codeowners
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	content := `# Default owners
+*       @octo-org/everyone
+
+*.js    @js-owner # frontend
+/docs/  @docs-team
+apps/   @octo-org/apps
+/build/logs/ @doctocat
+**/payments/** @octo-org/payments
+/scripts/*.sh @ops
+/vendor/
+`
+
+	file, err := Parse(content)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	testCases := []struct {
+		path            string
+		expectedPattern string
+		expectedOwners  []string
+	}{
+		{path: "README.md", expectedPattern: "*", expectedOwners: []string{"@octo-org/everyone"}},
+		{path: "src/app.js", expectedPattern: "*.js", expectedOwners: []string{"@js-owner"}},
+		{path: "docs/guide/setup.md", expectedPattern: "/docs/", expectedOwners: []string{"@docs-team"}},
+		{path: "/docs/index.md", expectedPattern: "/docs/", expectedOwners: []string{"@docs-team"}},
+		{path: "apps/web/main.go", expectedPattern: "apps/", expectedOwners: []string{"@octo-org/apps"}},
+		{path: "build/logs/today.log", expectedPattern: "/build/logs/", expectedOwners: []string{"@doctocat"}},
+		{path: "services/payments/refund.go", expectedPattern: "**/payments/**", expectedOwners: []string{"@octo-org/payments"}},
+		{path: "scripts/deploy.sh", expectedPattern: "/scripts/*.sh", expectedOwners: []string{"@ops"}},
+		{path: "scripts/ci/deploy.sh", expectedPattern: "*", expectedOwners: []string{"@octo-org/everyone"}},
+		{path: "vendor/lib/lib.go", expectedPattern: "/vendor/", expectedOwners: []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			rule := file.Match(tc.path)
+			if rule == nil {
+				t.Fatalf("Expected a matching rule for '%s', got nil", tc.path)
+			}
+			if rule.Pattern != tc.expectedPattern {
+				t.Errorf("Expected pattern '%s', got '%s'", tc.expectedPattern, rule.Pattern)
+			}
+			if !reflect.DeepEqual(rule.Owners, tc.expectedOwners) {
+				t.Errorf("Expected owners %v, got %v", tc.expectedOwners, rule.Owners)
+			}
+		})
+	}
+}
+
+func TestMatchDescendants(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		path     string
+		expected bool
+	}{
+		{pattern: "docs/*", path: "docs/index.md", expected: true},
+		{pattern: "docs/*", path: "docs/a/b.md", expected: false},
+		{pattern: "docs/", path: "docs/a/b.md", expected: true},
+		{pattern: "docs", path: "docs/a/b.md", expected: true},
+		{pattern: "/docs/*.md", path: "docs/a/b.md", expected: false},
+		{pattern: "docs/**", path: "docs/a/b.md", expected: true},
+		{pattern: "docs/*/", path: "docs/a/b.md", expected: true},
+		{pattern: "*.md", path: "docs/a/b.md", expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.pattern+" "+tc.path, func(t *testing.T) {
+			file, err := Parse(tc.pattern + " @owner\n")
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if matched := file.Match(tc.path) != nil; matched != tc.expected {
+				t.Errorf("Expected '%s' matching '%s' to be %v, got %v", tc.pattern, tc.path, tc.expected, matched)
+			}
+		})
+	}
+}
+
+func TestMatchWithoutRules(t *testing.T) {
+	file, err := Parse("# only comments\n\n")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	if rule := file.Match("main.go"); rule != nil {
+		t.Errorf("Expected no matching rule, got %v", rule)
+	}
+}
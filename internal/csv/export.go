@@ -0,0 +1,134 @@
+package csv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+// ExportColumns are the columns export can write, in their default order
+// The milestone column holds the title; creating issues needs the number,
+// which milestone_number holds.
+var ExportColumns = []string{"number", "title", "body", "labels", "assignees", "milestone", "milestone_number", "url", "state", "created_at"}
+
+// ParseColumns parses a comma-separated list of export columns. An empty list
+// selects every column.
+func ParseColumns(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return ExportColumns, nil
+	}
+
+	var columns []string
+	for _, column := range strings.Split(value, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !isExportColumn(column) {
+			return nil, fmt.Errorf("unknown column '%s' (available: %s)", column, strings.Join(ExportColumns, ", "))
+		}
+		columns = append(columns, column)
+	}
+	return columns, nil
+}
+
+// WriteIssues writes issues as CSV with a header row of the given columns.
+// Labels and assignees are joined with ", " like the front matter lists they
+// are read from.
+func WriteIssues(w io.Writer, issues []models.IssueDetails, columns []string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(columns); err != nil {
+		return err
+	}
+
+	for _, issue := range issues {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = exportValue(issue, column)
+		}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteRows writes a header row followed by rows as CSV
+func WriteRows(w io.Writer, headers []string, rows [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// ExistingIssue returns the number of the issue of repo that the url column
+// of an exported row links to. Re-importing rows exported from repo with
+// --skip-existing creates nothing; rows exported from other repositories are
+// not existing issues of repo.
+func ExistingIssue(issueURL string, repo models.RepoRef) (int, bool) {
+	ref, number, err := models.ParseIssueURL(issueURL)
+	if err != nil || !strings.EqualFold(ref.String(), repo.String()) {
+		return 0, false
+	}
+	return number, true
+}
+
+// exportValue returns the value of a column for an issue
+func exportValue(issue models.IssueDetails, column string) string {
+	switch column {
+	case "number":
+		return strconv.Itoa(issue.Number)
+	case "title":
+		return issue.Title
+	case "body":
+		return issue.Body
+	case "labels":
+		names := make([]string, len(issue.Labels))
+		for i, label := range issue.Labels {
+			names[i] = label.Name
+		}
+		return strings.Join(names, ", ")
+	case "assignees":
+		logins := make([]string, len(issue.Assignees))
+		for i, user := range issue.Assignees {
+			logins[i] = user.Login
+		}
+		return strings.Join(logins, ", ")
+	case "milestone":
+		if issue.Milestone == nil {
+			return ""
+		}
+		return issue.Milestone.Title
+	case "milestone_number":
+		if issue.Milestone == nil {
+			return ""
+		}
+		return strconv.Itoa(issue.Milestone.Number)
+	case "url":
+		return issue.URL
+	case "state":
+		return issue.State
+	case "created_at":
+		return issue.CreatedAt.UTC().Format(time.RFC3339)
+	default:
+		return ""
+	}
+}
+
+// isExportColumn reports whether column is one of ExportColumns
+func isExportColumn(column string) bool {
+	for _, c := range ExportColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,137 @@
+package csv
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns("")
+	if err != nil || !reflect.DeepEqual(columns, ExportColumns) {
+		t.Errorf("Expected all columns, got %v (%v)", columns, err)
+	}
+
+	columns, err = ParseColumns(" Title, labels ,url")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(columns, []string{"title", "labels", "url"}) {
+		t.Errorf("Expected [title labels url], got %v", columns)
+	}
+
+	if _, err := ParseColumns("title,author"); err == nil {
+		t.Error("Expected error for unknown column, got nil")
+	}
+}
+
+func TestWriteIssues(t *testing.T) {
+	issues := []models.IssueDetails{
+		{
+			Number:    12,
+			Title:     "Login fails",
+			Body:      "Steps:\n1. Click \"login\"",
+			Labels:    []models.Label{{Name: "bug"}, {Name: "frontend"}},
+			Assignees: []models.User{{Login: "alice"}, {Login: "bob"}},
+			Milestone: &models.Milestone{Number: 3, Title: "v1.0"},
+			URL:       "https://github.com/octo/api/issues/12",
+			State:     "open",
+			CreatedAt: time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{Number: 13, Title: "No labels", State: "closed"},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteIssues(&buf, issues, ExportColumns); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// The exported file reads back through the parser used for bulk creation
+	path := filepath.Join(t.TempDir(), "export.csv")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+	parser := NewParser()
+	records, headers, err := parser.Parse(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(headers, ExportColumns) {
+		t.Errorf("Expected headers %v, got %v", ExportColumns, headers)
+	}
+
+	rows := parser.MapRecords(records, headers)
+	expected := map[string]string{
+		"number":           "12",
+		"title":            "Login fails",
+		"body":             "Steps:\n1. Click \"login\"",
+		"labels":           "bug, frontend",
+		"assignees":        "alice, bob",
+		"milestone":        "v1.0",
+		"milestone_number": "3",
+		"url":              "https://github.com/octo/api/issues/12",
+		"state":            "open",
+		"created_at":       "2026-10-01T09:00:00Z",
+	}
+	if !reflect.DeepEqual(rows[0], expected) {
+		t.Errorf("Expected row %v, got %v", expected, rows[0])
+	}
+	if rows[1]["labels"] != "" || rows[1]["milestone"] != "" || rows[1]["milestone_number"] != "" {
+		t.Errorf("Expected empty labels and milestone, got %v", rows[1])
+	}
+}
+
+func TestWriteRows(t *testing.T) {
+	var out bytes.Buffer
+	err := WriteRows(&out, []string{"title", "due"}, [][]string{{"Write docs, then ship", "2026-12-01"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := "title,due\n\"Write docs, then ship\",2026-12-01\n"
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestExistingIssue(t *testing.T) {
+	issues := []models.IssueDetails{
+		{Number: 12, Title: "Login fails", URL: "https://github.com/octo/api/issues/12"},
+		{Number: 13, Title: "Logout fails", URL: "https://github.com/octo/api/issues/13"},
+	}
+	var buf bytes.Buffer
+	if err := WriteIssues(&buf, issues, []string{"title", "url"}); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "export.csv")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		t.Fatalf("Failed to write CSV: %v", err)
+	}
+	parser := NewParser()
+	records, headers, err := parser.Parse(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// Every exported row is an existing issue of the repository it was
+	// exported from, and of no other
+	api := models.RepoRef{Host: "github.com", Owner: "Octo", Name: "API"}
+	web := models.RepoRef{Host: "github.com", Owner: "octo", Name: "web"}
+	for i, row := range parser.MapRecords(records, headers) {
+		if number, ok := ExistingIssue(row["url"], api); !ok || number != issues[i].Number {
+			t.Errorf("Expected row %d to be #%d of octo/api, got %d, %v", i+1, issues[i].Number, number, ok)
+		}
+		if _, ok := ExistingIssue(row["url"], web); ok {
+			t.Errorf("Expected row %d not to be an issue of octo/web", i+1)
+		}
+	}
+
+	if _, ok := ExistingIssue("", api); ok {
+		t.Error("Expected a row without url not to be an existing issue")
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -66,51 +67,62 @@ func (p *Parser) Parse(filePath string) ([][]string, []string, error) {
 	return records, headers, nil
 }
 
-// ValidateHeadersAgainstTemplate validates that CSV headers match the variables in the template
-func (p *Parser) ValidateHeadersAgainstTemplate(headers []string, templateVars []string) ([]string, error) {
+// HeaderCheck is the result of comparing CSV headers with template variables
+type HeaderCheck struct {
+	// Unused are the CSV headers no template variable refers to
+	Unused []string
+	// Missing are the template variables no CSV header provides
+	Missing []string
+}
+
+// Warnings formats the unused headers and missing variables as warnings
+func (c *HeaderCheck) Warnings() []string {
+	var warnings []string
+	if len(c.Unused) > 0 {
+		warnings = append(warnings, fmt.Sprintf("Warning: The following CSV headers are not used in the template: %s", strings.Join(c.Unused, ", ")))
+	}
+	if len(c.Missing) > 0 {
+		warnings = append(warnings, fmt.Sprintf("Warning: The following template variables are missing from CSV headers: %s", strings.Join(c.Missing, ", ")))
+	}
+	return warnings
+}
+
+// CompareHeaders compares CSV headers with the variables of a template
+func (p *Parser) CompareHeaders(headers []string, templateVars []string) (*HeaderCheck, error) {
 	if len(headers) == 0 {
 		return nil, errors.New("CSV has no headers")
 	}
 
-	// Create a map of template variables for quick lookup
 	templateVarMap := make(map[string]bool)
 	for _, v := range templateVars {
 		templateVarMap[v] = true
 	}
+	headerMap := make(map[string]bool)
+	for _, h := range headers {
+		headerMap[h] = true
+	}
 
-	// Check if each header exists in the template variables
-	var missingVars []string
-	var missingHeaders []string
-
-	// Check for CSV headers that don't exist in template
+	check := &HeaderCheck{}
 	for _, header := range headers {
 		if !templateVarMap[header] {
-			missingVars = append(missingVars, header)
+			check.Unused = append(check.Unused, header)
 		}
 	}
-
-	// Check for template variables that don't exist in CSV headers
-	headerMap := make(map[string]bool)
-	for _, h := range headers {
-		headerMap[h] = true
-	}
 	for _, v := range templateVars {
 		if !headerMap[v] {
-			missingHeaders = append(missingHeaders, v)
+			check.Missing = append(check.Missing, v)
 		}
 	}
+	return check, nil
+}
 
-	var warnings []string
-
-	if len(missingVars) > 0 {
-		warnings = append(warnings, fmt.Sprintf("Warning: The following CSV headers are not used in the template: %s", strings.Join(missingVars, ", ")))
-	}
-
-	if len(missingHeaders) > 0 {
-		warnings = append(warnings, fmt.Sprintf("Warning: The following template variables are missing from CSV headers: %s", strings.Join(missingHeaders, ", ")))
+// ValidateHeadersAgainstTemplate validates that CSV headers match the variables in the template
+func (p *Parser) ValidateHeadersAgainstTemplate(headers []string, templateVars []string) ([]string, error) {
+	check, err := p.CompareHeaders(headers, templateVars)
+	if err != nil {
+		return nil, err
 	}
-
-	return warnings, nil
+	return check.Warnings(), nil
 }
 
 // MapRecords converts CSV records to maps using headers as keys
@@ -129,3 +141,88 @@ func (p *Parser) MapRecords(records [][]string, headers []string) []map[string]s
 
 	return result
 }
+
+// Group holds the mapped records that share the same value in a grouping column
+type Group struct {
+	Key  string
+	Rows []map[string]string
+	// Indexes holds the 1-based position of each row in the original records
+	Indexes []int
+}
+
+// GroupRecords groups mapped records by the value of column, keeping the
+// order in which each value first appears
+func (p *Parser) GroupRecords(dataMaps []map[string]string, column string) []*Group {
+	var groups []*Group
+	index := make(map[string]*Group)
+
+	for i, data := range dataMaps {
+		key := strings.TrimSpace(data[column])
+		group, ok := index[key]
+		if !ok {
+			group = &Group{Key: key}
+			index[key] = group
+			groups = append(groups, group)
+		}
+		group.Rows = append(group.Rows, data)
+		group.Indexes = append(group.Indexes, i+1)
+	}
+
+	return groups
+}
+
+// DefaultListColumns are the columns whose cells hold comma-separated lists
+// when grouping, matched ignoring case
+var DefaultListColumns = []string{"label", "labels", "assignee", "assignees"}
+
+// Data returns the template variables for a group: the values of its first
+// row, "rows.<column>" with the distinct non-empty values of each column joined
+// by ", ", and "rows.count" with the number of rows. Cells of listColumns
+// (matched ignoring case) are comma-separated lists whose items are
+// de-duplicated one by one; other cells are compared whole, so that values
+// such as titles may contain commas.
+func (g *Group) Data(listColumns []string) map[string]string {
+	data := make(map[string]string)
+	if len(g.Rows) > 0 {
+		for key, value := range g.Rows[0] {
+			data[key] = value
+		}
+	}
+
+	isList := func(column string) bool {
+		for _, c := range listColumns {
+			if strings.EqualFold(c, column) {
+				return true
+			}
+		}
+		return false
+	}
+
+	columns := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, row := range g.Rows {
+		for key, value := range row {
+			if _, ok := columns[key]; !ok {
+				columns[key] = nil
+			}
+			items := []string{value}
+			if isList(key) {
+				items = strings.Split(value, ",")
+			}
+			for _, item := range items {
+				item = strings.TrimSpace(item)
+				if item == "" || seen[key+"\x00"+item] {
+					continue
+				}
+				seen[key+"\x00"+item] = true
+				columns[key] = append(columns[key], item)
+			}
+		}
+	}
+	for key, values := range columns {
+		data["rows."+key] = strings.Join(values, ", ")
+	}
+	data["rows.count"] = strconv.Itoa(len(g.Rows))
+
+	return data
+}
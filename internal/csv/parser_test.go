@@ -91,6 +91,27 @@ func TestValidateHeadersAgainstTemplate(t *testing.T) {
 	}
 }
 
+func TestCompareHeaders(t *testing.T) {
+	check, err := NewParser().CompareHeaders([]string{"title", "extra1", "extra2"}, []string{"title", "description", "steps"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(check.Unused, []string{"extra1", "extra2"}) {
+		t.Errorf("Expected unused [extra1 extra2], got %v", check.Unused)
+	}
+	if !reflect.DeepEqual(check.Missing, []string{"description", "steps"}) {
+		t.Errorf("Expected missing [description steps], got %v", check.Missing)
+	}
+
+	expected := []string{
+		"Warning: The following CSV headers are not used in the template: extra1, extra2",
+		"Warning: The following template variables are missing from CSV headers: description, steps",
+	}
+	if !reflect.DeepEqual(check.Warnings(), expected) {
+		t.Errorf("Expected warnings %v, got %v", expected, check.Warnings())
+	}
+}
+
 func TestParse(t *testing.T) {
 	// Create a temporary CSV file for testing
 	tmpFile, err := os.CreateTemp("", "test-*.csv")
@@ -246,3 +267,54 @@ func TestMapRecordsWithMissingValues(t *testing.T) {
 		t.Errorf("Expected mapped records %v, got %v", expected, result)
 	}
 }
+
+func TestGroupRecords(t *testing.T) {
+	dataMaps := []map[string]string{
+		{"component": "payments", "title": "Fix login, again", "label": "bug"},
+		{"component": "ui", "title": "B", "label": "ui, bug"},
+		{"component": "payments", "title": "C", "label": "perf, bug"},
+		{"component": "payments", "title": "Fix login, again", "label": ""},
+	}
+
+	parser := NewParser()
+	groups := parser.GroupRecords(dataMaps, "component")
+
+	if len(groups) != 2 {
+		t.Fatalf("Expected 2 groups, got %d", len(groups))
+	}
+
+	if groups[0].Key != "payments" || groups[1].Key != "ui" {
+		t.Errorf("Expected groups in first-appearance order, got %s, %s", groups[0].Key, groups[1].Key)
+	}
+
+	if !reflect.DeepEqual(groups[0].Indexes, []int{1, 3, 4}) {
+		t.Errorf("Expected payments rows [1 3 4], got %v", groups[0].Indexes)
+	}
+
+	// Only list columns are split on commas
+	data := groups[0].Data(DefaultListColumns)
+	expected := map[string]string{
+		"component":      "payments",
+		"title":          "Fix login, again",
+		"label":          "bug",
+		"rows.component": "payments",
+		"rows.title":     "Fix login, again, C",
+		"rows.label":     "bug, perf",
+		"rows.count":     "3",
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("Expected group data %v, got %v", expected, data)
+	}
+
+	if label := groups[1].Data(DefaultListColumns)["rows.label"]; label != "ui, bug" {
+		t.Errorf("Expected ui labels 'ui, bug', got '%s'", label)
+	}
+
+	// Without list columns, cells are compared whole
+	if label := groups[0].Data(nil)["rows.label"]; label != "bug, perf, bug" {
+		t.Errorf("Expected whole label cells 'bug, perf, bug', got '%s'", label)
+	}
+	if title := groups[0].Data([]string{"TITLE"})["rows.title"]; title != "Fix login, again, C" {
+		t.Errorf("Expected title items 'Fix login, again, C', got '%s'", title)
+	}
+}
@@ -0,0 +1,356 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Expression is a parsed --where expression. It only reads row fields, so it
+// is safe to evaluate on untrusted data.
+//
+// Operands are column names (`quoted in backticks` if they contain spaces),
+// "strings" or 'strings', and numbers. Comparisons are ==, !=, <, <=, >, >=
+// (numeric when both sides are numbers), =~ and !~ (regular expression
+// match). A lone operand is true when it is not empty. Conditions combine with
+// !, && and || and group with parentheses.
+type Expression struct {
+	source string
+	root   node
+	fields []string
+}
+
+// node is a boolean expression over a row
+type node interface {
+	eval(row map[string]string) bool
+}
+
+// operand is a column reference or a literal value
+type operand struct {
+	field   string
+	literal string
+	isField bool
+}
+
+func (o operand) value(row map[string]string) string {
+	if o.isField {
+		return row[o.field]
+	}
+	return o.literal
+}
+
+type notNode struct{ inner node }
+
+func (n notNode) eval(row map[string]string) bool { return !n.inner.eval(row) }
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(row map[string]string) bool { return n.left.eval(row) && n.right.eval(row) }
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(row map[string]string) bool { return n.left.eval(row) || n.right.eval(row) }
+
+type truthyNode struct{ operand operand }
+
+func (n truthyNode) eval(row map[string]string) bool {
+	return strings.TrimSpace(n.operand.value(row)) != ""
+}
+
+type compareNode struct {
+	left, right operand
+	op          string
+}
+
+func (n compareNode) eval(row map[string]string) bool {
+	left, right := n.left.value(row), n.right.value(row)
+	cmp := strings.Compare(left, right)
+	if l, err := strconv.ParseFloat(strings.TrimSpace(left), 64); err == nil {
+		if r, err := strconv.ParseFloat(strings.TrimSpace(right), 64); err == nil {
+			switch {
+			case l < r:
+				cmp = -1
+			case l > r:
+				cmp = 1
+			default:
+				cmp = 0
+			}
+		}
+	}
+
+	switch n.op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+type matchNode struct {
+	operand operand
+	pattern *regexp.Regexp
+	negate  bool
+}
+
+func (n matchNode) eval(row map[string]string) bool {
+	return n.pattern.MatchString(n.operand.value(row)) != n.negate
+}
+
+// ParseExpression parses a --where expression
+func ParseExpression(source string) (*Expression, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression '%s': %v", source, err)
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("invalid expression '%s': expression is empty", source)
+	}
+
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %s", p.tokens[p.pos])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression '%s': %v", source, err)
+	}
+	return &Expression{source: source, root: root, fields: p.fields}, nil
+}
+
+// Match reports whether the row satisfies the expression. Columns missing
+// from the row are empty.
+func (e *Expression) Match(row map[string]string) bool {
+	return e.root.eval(row)
+}
+
+// Fields returns the column names the expression reads, in order of
+// appearance
+func (e *Expression) Fields() []string {
+	return e.fields
+}
+
+// String returns the expression as written
+func (e *Expression) String() string {
+	return e.source
+}
+
+// tokenKind distinguishes the tokens of an expression
+type tokenKind int
+
+const (
+	tokenField tokenKind = iota
+	tokenString
+	tokenNumber
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenString:
+		return strconv.Quote(t.text)
+	case tokenField:
+		return fmt.Sprintf("column '%s'", t.text)
+	default:
+		return fmt.Sprintf("'%s'", t.text)
+	}
+}
+
+// operators lists the operator tokens, longest first
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!", "(", ")"}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '"' || r == '\'' || r == '`':
+			var b strings.Builder
+			j := i + 1
+			for ; j < len(runes) && runes[j] != r; j++ {
+				// Only quotes and backslashes are escaped; other escapes, such
+				// as \d in a regular expression, are kept as written
+				if runes[j] == '\\' && j+1 < len(runes) && (runes[j+1] == r || strings.ContainsRune(`"'\`, runes[j+1])) {
+					j++
+				}
+				b.WriteRune(runes[j])
+			}
+			if j >= len(runes) {
+				return nil, fmt.Errorf("unterminated %c", r)
+			}
+			kind := tokenString
+			if r == '`' {
+				kind = tokenField
+			}
+			tokens = append(tokens, token{kind: kind, text: b.String()})
+			i = j + 1
+		case unicode.IsDigit(r) || (r == '-' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[i:j])})
+			i = j
+		case unicode.IsLetter(r) || r == '_':
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || strings.ContainsRune("_.-", runes[j])) {
+				j++
+			}
+			tokens = append(tokens, token{kind: tokenField, text: string(runes[i:j])})
+			i = j
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(string(runes[i:]), op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op})
+					i += len([]rune(op))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected '%c'", r)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+// exprParser is a recursive descent parser over the tokens of an expression
+type exprParser struct {
+	tokens []token
+	pos    int
+	fields []string
+}
+
+// accept consumes the next token if it is the operator op
+func (p *exprParser) accept(op string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokenOperator && p.tokens[p.pos].text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary() (node, error) {
+	if p.accept("!") {
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner}, nil
+	}
+	if p.accept("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *exprParser) parseComparison() (node, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if p.accept(op) {
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			return compareNode{left: left, right: right, op: op}, nil
+		}
+	}
+	for _, op := range []string{"=~", "!~"} {
+		if p.accept(op) {
+			right, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			if right.isField {
+				return nil, fmt.Errorf("%s needs a quoted regular expression", op)
+			}
+			pattern, err := regexp.Compile(right.literal)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regular expression: %v", err)
+			}
+			return matchNode{operand: left, pattern: pattern, negate: op == "!~"}, nil
+		}
+	}
+	return truthyNode{operand: left}, nil
+}
+
+func (p *exprParser) parseOperand() (operand, error) {
+	if p.pos >= len(p.tokens) {
+		return operand{}, fmt.Errorf("unexpected end of expression")
+	}
+	t := p.tokens[p.pos]
+	switch t.kind {
+	case tokenField:
+		p.pos++
+		if !slices.Contains(p.fields, t.text) {
+			p.fields = append(p.fields, t.text)
+		}
+		return operand{field: t.text, isField: true}, nil
+	case tokenString, tokenNumber:
+		p.pos++
+		return operand{literal: t.text}, nil
+	default:
+		return operand{}, fmt.Errorf("unexpected %s", t)
+	}
+}
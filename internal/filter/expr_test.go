@@ -0,0 +1,99 @@
+package filter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpression(t *testing.T) {
+	row := map[string]string{
+		"priority": "P1",
+		"status":   "done",
+		"estimate": "8",
+		"Due date": "2026-11-01",
+		"notes":    "  ",
+		"title":    "Fix login on Safari",
+		"id":       "1234",
+		"path":     `C:\data`,
+	}
+
+	testCases := []struct {
+		expr     string
+		expected bool
+	}{
+		{expr: `priority == "P1"`, expected: true},
+		{expr: `priority == 'P2'`, expected: false},
+		{expr: `status != "done"`, expected: false},
+		{expr: `!(status == "done")`, expected: false},
+		{expr: `priority == "P1" && status != "done"`, expected: false},
+		{expr: `priority == "P1" || status != "done"`, expected: true},
+		{expr: `status == "open" || priority == "P2" && estimate > 5`, expected: false},
+		{expr: `(status == "open" || priority == "P1") && estimate > 5`, expected: true},
+		{expr: `estimate > 10`, expected: false},
+		{expr: `estimate >= 8`, expected: true},
+		{expr: `estimate < 10`, expected: true},
+		{expr: `estimate <= -1`, expected: false},
+		{expr: "`Due date` < \"2026-12-01\"", expected: true},
+		{expr: `title =~ "(?i)safari"`, expected: true},
+		{expr: `title !~ "Chrome"`, expected: true},
+		{expr: `notes`, expected: false},
+		{expr: `!notes`, expected: true},
+		{expr: `priority`, expected: true},
+		{expr: `missing == ""`, expected: true},
+		{expr: `"a\"b" == 'a"b'`, expected: true},
+		{expr: `id =~ "^\d+$"`, expected: true},
+		{expr: `title =~ "^\d+$"`, expected: false},
+		{expr: `title =~ "\bSafari\b"`, expected: true},
+		{expr: `path == "C:\\data"`, expected: true},
+		{expr: `'it\'s' == "it's"`, expected: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			expr, err := ParseExpression(tc.expr)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if matched := expr.Match(row); matched != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, matched)
+			}
+		})
+	}
+}
+
+func TestExpressionErrors(t *testing.T) {
+	testCases := []struct {
+		expr string
+		err  string
+	}{
+		{expr: "", err: "expression is empty"},
+		{expr: `priority ==`, err: "unexpected end of expression"},
+		{expr: `priority = "P1"`, err: "unexpected '='"},
+		{expr: `(priority == "P1"`, err: "missing ')'"},
+		{expr: `priority == "P1")`, err: "unexpected ')'"},
+		{expr: `priority == "P1`, err: "unterminated \""},
+		{expr: `title =~ pattern`, err: "=~ needs a quoted regular expression"},
+		{expr: `title =~ "("`, err: "invalid regular expression"},
+		{expr: `&& status`, err: "unexpected '&&'"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expr, func(t *testing.T) {
+			_, err := ParseExpression(tc.expr)
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("Expected error containing %q, got: %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestExpressionFields(t *testing.T) {
+	expr, err := ParseExpression("priority == \"P1\" && (status != 'done' || `Due date` == priority)")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if expected := []string{"priority", "status", "Due date"}; !reflect.DeepEqual(expr.Fields(), expected) {
+		t.Errorf("Expected fields %v, got %v", expected, expr.Fields())
+	}
+}
@@ -0,0 +1,164 @@
+// Package filter selects the CSV rows to create issues from by row number,
+// by an expression over row fields and by count.
+package filter
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RowRange is an inclusive range of 1-based row numbers. End is 0 for a range
+// open to the last row.
+type RowRange struct {
+	Start int
+	End   int
+}
+
+// RowSet is a list of row ranges, such as "10-20,35"
+type RowSet []RowRange
+
+// ParseRows parses a comma-separated list of row numbers and ranges: "N",
+// "N-M" and "N-" (from N to the last row)
+func ParseRows(spec string) (RowSet, error) {
+	var set RowSet
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		startText, endText, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(strings.TrimSpace(startText))
+		if err != nil || start < 1 {
+			return nil, fmt.Errorf("invalid row '%s': rows are numbered from 1", part)
+		}
+		end := start
+		if isRange {
+			end = 0
+			if endText = strings.TrimSpace(endText); endText != "" {
+				end, err = strconv.Atoi(endText)
+				if err != nil || end < start {
+					return nil, fmt.Errorf("invalid row range '%s'", part)
+				}
+			}
+		}
+		set = append(set, RowRange{Start: start, End: end})
+	}
+	if len(set) == 0 {
+		return nil, fmt.Errorf("no rows given")
+	}
+	return set, nil
+}
+
+// Contains reports whether the set contains row number n
+func (s RowSet) Contains(n int) bool {
+	for _, r := range s {
+		if n >= r.Start && (r.End == 0 || n <= r.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// String formats the set as it is written on the command line
+func (s RowSet) String() string {
+	parts := make([]string, len(s))
+	for i, r := range s {
+		switch {
+		case r.End == 0:
+			parts[i] = fmt.Sprintf("%d-", r.Start)
+		case r.End == r.Start:
+			parts[i] = strconv.Itoa(r.Start)
+		default:
+			parts[i] = fmt.Sprintf("%d-%d", r.Start, r.End)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Exclusion records a row that was not selected and why
+type Exclusion struct {
+	Row    int
+	Reason string
+}
+
+// Selector picks rows by --rows, then --where, then --limit. Unset criteria
+// select every row.
+type Selector struct {
+	rows  RowSet
+	where *Expression
+	limit int
+}
+
+// NewSelector creates a selector from the --rows, --where and --limit options.
+// Empty strings and a zero limit leave that criterion unset.
+func NewSelector(rows string, where string, limit int) (*Selector, error) {
+	s := &Selector{limit: limit}
+	if limit < 0 {
+		return nil, fmt.Errorf("--limit must not be negative")
+	}
+	if rows != "" {
+		set, err := ParseRows(rows)
+		if err != nil {
+			return nil, fmt.Errorf("--rows: %v", err)
+		}
+		s.rows = set
+	}
+	if where != "" {
+		expr, err := ParseExpression(where)
+		if err != nil {
+			return nil, fmt.Errorf("--where: %v", err)
+		}
+		s.where = expr
+	}
+	return s, nil
+}
+
+// IsEmpty reports whether the selector selects every row
+func (s *Selector) IsEmpty() bool {
+	return s.rows == nil && s.where == nil && s.limit == 0
+}
+
+// CheckColumns reports columns read by --where that are not in headers
+func (s *Selector) CheckColumns(headers []string) error {
+	if s.where == nil {
+		return nil
+	}
+	for _, field := range s.where.Fields() {
+		if !slices.Contains(headers, field) {
+			return fmt.Errorf("--where: column '%s' not found in CSV headers", field)
+		}
+	}
+	return nil
+}
+
+// WhereColumns returns the columns read by --where
+func (s *Selector) WhereColumns() []string {
+	if s.where == nil {
+		return nil
+	}
+	return s.where.Fields()
+}
+
+// Select returns the 1-based numbers of the selected rows in order, and the
+// rows left out with the reason
+func (s *Selector) Select(rows []map[string]string) ([]int, []Exclusion) {
+	var selected []int
+	var excluded []Exclusion
+	for i, row := range rows {
+		n := i + 1
+		switch {
+		case s.rows != nil && !s.rows.Contains(n):
+			excluded = append(excluded, Exclusion{Row: n, Reason: fmt.Sprintf("not in --rows %s", s.rows)})
+		case s.where != nil && !s.where.Match(row):
+			excluded = append(excluded, Exclusion{Row: n, Reason: fmt.Sprintf("does not match --where %s", s.where)})
+		case s.limit > 0 && len(selected) >= s.limit:
+			excluded = append(excluded, Exclusion{Row: n, Reason: fmt.Sprintf("over --limit %d", s.limit)})
+		default:
+			selected = append(selected, n)
+		}
+	}
+	return selected, excluded
+}
@@ -0,0 +1,143 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseRows(t *testing.T) {
+	testCases := []struct {
+		spec     string
+		expected string
+		rows     []int
+		err      bool
+	}{
+		{spec: "10-20,35", expected: "10-20,35", rows: []int{10, 15, 20, 35}},
+		{spec: " 3 , 5-5 ", expected: "3,5", rows: []int{3, 5}},
+		{spec: "7-", expected: "7-", rows: []int{7, 100}},
+		{spec: "", err: true},
+		{spec: "0", err: true},
+		{spec: "a-3", err: true},
+		{spec: "5-3", err: true},
+		{spec: "-3", err: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.spec, func(t *testing.T) {
+			set, err := ParseRows(tc.spec)
+			if tc.err {
+				if err == nil {
+					t.Errorf("Expected error, got %v", set)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if set.String() != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, set)
+			}
+			for _, n := range tc.rows {
+				if !set.Contains(n) {
+					t.Errorf("Expected %s to contain row %d", set, n)
+				}
+			}
+			if set.Contains(tc.rows[0] - 1) {
+				t.Errorf("Expected %s not to contain row %d", set, tc.rows[0]-1)
+			}
+		})
+	}
+}
+
+func TestSelector(t *testing.T) {
+	rows := []map[string]string{
+		{"title": "A", "status": "done"},
+		{"title": "B", "status": "open"},
+		{"title": "C", "status": ""},
+		{"title": "D", "status": "open"},
+		{"title": "E", "status": "open"},
+	}
+
+	testCases := []struct {
+		name     string
+		rows     string
+		where    string
+		limit    int
+		selected []int
+		excluded []Exclusion
+	}{
+		{
+			name:     "No criteria",
+			selected: []int{1, 2, 3, 4, 5},
+		},
+		{
+			name:     "Rows",
+			rows:     "2-3,5",
+			selected: []int{2, 3, 5},
+			excluded: []Exclusion{
+				{Row: 1, Reason: "not in --rows 2-3,5"},
+				{Row: 4, Reason: "not in --rows 2-3,5"},
+			},
+		},
+		{
+			name:     "Rows, where and limit",
+			rows:     "2-",
+			where:    `status != "done"`,
+			limit:    2,
+			selected: []int{2, 3},
+			excluded: []Exclusion{
+				{Row: 1, Reason: "not in --rows 2-"},
+				{Row: 4, Reason: "over --limit 2"},
+				{Row: 5, Reason: "over --limit 2"},
+			},
+		},
+		{
+			name:     "Where",
+			where:    `status == "open"`,
+			selected: []int{2, 4, 5},
+			excluded: []Exclusion{
+				{Row: 1, Reason: `does not match --where status == "open"`},
+				{Row: 3, Reason: `does not match --where status == "open"`},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			selector, err := NewSelector(tc.rows, tc.where, tc.limit)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			selected, excluded := selector.Select(rows)
+			if !reflect.DeepEqual(selected, tc.selected) {
+				t.Errorf("Expected selected rows %v, got %v", tc.selected, selected)
+			}
+			if !reflect.DeepEqual(excluded, tc.excluded) {
+				t.Errorf("Expected excluded rows %v, got %v", tc.excluded, excluded)
+			}
+		})
+	}
+}
+
+func TestSelectorErrors(t *testing.T) {
+	if _, err := NewSelector("x", "", 0); err == nil {
+		t.Error("Expected error for invalid --rows, got nil")
+	}
+	if _, err := NewSelector("", "status ==", 0); err == nil {
+		t.Error("Expected error for invalid --where, got nil")
+	}
+	if _, err := NewSelector("", "", -1); err == nil {
+		t.Error("Expected error for negative --limit, got nil")
+	}
+
+	selector, err := NewSelector("", `status == "open" && owner`, 0)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if err := selector.CheckColumns([]string{"title", "status", "owner"}); err != nil {
+		t.Errorf("Expected no error, got: %v", err)
+	}
+	if err := selector.CheckColumns([]string{"title", "status"}); err == nil || err.Error() != "--where: column 'owner' not found in CSV headers" {
+		t.Errorf("Expected unknown column error, got: %v", err)
+	}
+}
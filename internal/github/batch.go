@@ -0,0 +1,462 @@
+package github
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+// DefaultBatchSize is the number of issues created per GraphQL request
+const DefaultBatchSize = 25
+
+// ErrUnknownOutcome marks the issues of a request that failed as a whole, for
+// example on a timeout or a 502 response. They may or may not have been
+// created, so the repository has to be checked.
+var ErrUnknownOutcome = errors.New("outcome unknown, check the repository")
+
+// ErrNotSent marks the issues that were not sent because an earlier request
+// had an unknown outcome
+var ErrNotSent = errors.New("not sent after a request with an unknown outcome")
+
+// IssueRequest is an issue to create in a repository
+type IssueRequest struct {
+	Issue *models.Issue
+	Repo  string
+}
+
+// IssueResult is the outcome of creating a single issue
+type IssueResult struct {
+	Response *models.IssueResponse
+	Err      error
+}
+
+// CreateIssues creates issues one at a time through the REST API
+func (c *Client) CreateIssues(requests []IssueRequest) []IssueResult {
+	results := make([]IssueResult, len(requests))
+	for i, request := range requests {
+		results[i].Response, results[i].Err = c.CreateIssue(request.Issue, request.Repo)
+	}
+	return results
+}
+
+// GraphQLIssueClient creates issues through the GraphQL API, sending several
+// aliased createIssue mutations per request. Everything else is done through
+// the REST client it wraps.
+type GraphQLIssueClient struct {
+	*Client
+	batchSize int
+	repos     map[string]*issueRepository
+}
+
+// issueRepository holds the node IDs needed to create issues in a repository.
+// A missing entry has not been looked up yet; an empty ID was not found.
+type issueRepository struct {
+	id         string
+	labels     map[string]string
+	milestones map[string]string
+	users      map[string]string
+}
+
+// NewGraphQLIssueClient creates a GraphQL issue client sending batchSize
+// mutations per request
+func NewGraphQLIssueClient(client *Client, batchSize int) *GraphQLIssueClient {
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	return &GraphQLIssueClient{
+		Client:    client,
+		batchSize: batchSize,
+		repos:     make(map[string]*issueRepository),
+	}
+}
+
+// CreateIssue creates a single issue through the GraphQL API
+func (c *GraphQLIssueClient) CreateIssue(issue *models.Issue, repo string) (*models.IssueResponse, error) {
+	result := c.CreateIssues([]IssueRequest{{Issue: issue, Repo: repo}})[0]
+	return result.Response, result.Err
+}
+
+// ResolveIssueIDs looks up the repository, label, assignee and milestone IDs of
+// every request without changing anything. Labels that do not exist are not an
+// error: they are created, as the REST API does, just before the first batch
+// that uses them. It returns an error per request, or nil if every request can
+// be created.
+func (c *GraphQLIssueClient) ResolveIssueIDs(requests []IssueRequest) []error {
+	errs := make([]error, len(requests))
+	failed := false
+	for i, request := range requests {
+		if _, err := c.lookUp(request); err != nil {
+			errs[i] = err
+			failed = true
+		}
+	}
+	if !failed {
+		return nil
+	}
+	return errs
+}
+
+// CreateIssues creates issues in batches of aliased mutations. Results are in
+// the same order as the requests, and a failure only affects its own request,
+// unless a request fails as a whole: its issues get ErrUnknownOutcome and the
+// requests after it are not sent and get ErrNotSent.
+func (c *GraphQLIssueClient) CreateIssues(requests []IssueRequest) []IssueResult {
+	results := make([]IssueResult, len(requests))
+
+	// Requests are batched per host, keeping their order within each host
+	type pending struct {
+		index   int
+		request IssueRequest
+	}
+	var hosts []*Client
+	batches := make(map[*Client][]pending)
+	for i, request := range requests {
+		host, _, err := c.forRepo(request.Repo)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		if _, ok := batches[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		batches[host] = append(batches[host], pending{index: i, request: request})
+	}
+
+	stopped := false
+	for _, host := range hosts {
+		items := batches[host]
+		for start := 0; start < len(items); start += c.batchSize {
+			end := min(start+c.batchSize, len(items))
+
+			// Inputs are built per batch so that labels are only created
+			// right before the issues using them
+			var inputs []map[string]interface{}
+			var indexes []int
+			for _, item := range items[start:end] {
+				if stopped {
+					results[item.index].Err = ErrNotSent
+					continue
+				}
+				input, err := c.issueInput(item.request)
+				if err != nil {
+					results[item.index].Err = err
+					continue
+				}
+				inputs = append(inputs, input)
+				indexes = append(indexes, item.index)
+			}
+			if len(inputs) == 0 {
+				continue
+			}
+
+			for j, result := range createIssueBatch(host, inputs) {
+				results[indexes[j]] = result
+				if errors.Is(result.Err, ErrUnknownOutcome) {
+					stopped = true
+				}
+			}
+		}
+	}
+
+	return results
+}
+
+// createIssueBatch sends one request with a createIssue mutation per input,
+// aliased i0, i1, ... so that errors can be mapped back to their input
+func createIssueBatch(host *Client, inputs []map[string]interface{}) []IssueResult {
+	results := make([]IssueResult, len(inputs))
+	if host.graphql == nil {
+		for i := range results {
+			results[i].Err = fmt.Errorf("GraphQL client is not initialized")
+		}
+		return results
+	}
+
+	var declarations, mutations []string
+	variables := make(map[string]interface{}, len(inputs))
+	for i, input := range inputs {
+		alias := fmt.Sprintf("i%d", i)
+		declarations = append(declarations, fmt.Sprintf("$%s: CreateIssueInput!", alias))
+		mutations = append(mutations, fmt.Sprintf("  %s: createIssue(input: $%s) { issue { id number url } }", alias, alias))
+		variables[alias] = input
+	}
+	query := fmt.Sprintf("mutation(%s) {\n%s\n}", strings.Join(declarations, ", "), strings.Join(mutations, "\n"))
+
+	var response map[string]*struct {
+		Issue *struct {
+			ID     string `json:"id"`
+			Number int    `json:"number"`
+			URL    string `json:"url"`
+		} `json:"issue"`
+	}
+	err := host.graphql.Do(query, variables, &response)
+
+	// Errors with a path belong to the mutation of that alias
+	aliasErrors := make(map[string]error)
+	var gqlErr *api.GraphQLError
+	if errors.As(err, &gqlErr) {
+		for _, item := range gqlErr.Errors {
+			if len(item.Path) == 0 {
+				continue
+			}
+			if alias, ok := item.Path[0].(string); ok {
+				aliasErrors[alias] = errors.New(item.Message)
+			}
+		}
+	}
+
+	for i := range inputs {
+		alias := fmt.Sprintf("i%d", i)
+		if created := response[alias]; created != nil && created.Issue != nil {
+			results[i].Response = &models.IssueResponse{
+				Number: created.Issue.Number,
+				URL:    created.Issue.URL,
+				NodeID: created.Issue.ID,
+			}
+			continue
+		}
+		switch {
+		case aliasErrors[alias] != nil:
+			results[i].Err = aliasErrors[alias]
+		case err != nil && gqlErr == nil:
+			// The request failed as a whole, possibly after GitHub handled it
+			results[i].Err = fmt.Errorf("%w: %v", ErrUnknownOutcome, err)
+		case err != nil:
+			results[i].Err = err
+		default:
+			results[i].Err = fmt.Errorf("no issue was returned")
+		}
+	}
+
+	return results
+}
+
+// lookUp looks up the IDs of a request, checking that its repository,
+// assignees and milestone exist. Labels that do not exist are left to be
+// created by issueInput.
+func (c *GraphQLIssueClient) lookUp(request IssueRequest) (*issueRepository, error) {
+	issue := request.Issue
+	info, err := c.issueRepository(request.Repo, issue)
+	if err != nil {
+		return nil, err
+	}
+	for _, login := range issue.Assignees {
+		if info.users[strings.ToLower(login)] == "" {
+			return nil, fmt.Errorf("assignee '%s' not found", login)
+		}
+	}
+	if issue.Milestone != "" && info.milestones[issue.Milestone] == "" {
+		return nil, fmt.Errorf("milestone %s not found in %s", issue.Milestone, request.Repo)
+	}
+	return info, nil
+}
+
+// issueInput builds the CreateIssueInput of a request from cached node IDs,
+// looking up anything that is not cached yet and creating missing labels
+func (c *GraphQLIssueClient) issueInput(request IssueRequest) (map[string]interface{}, error) {
+	issue := request.Issue
+	info, err := c.lookUp(request)
+	if err != nil {
+		return nil, err
+	}
+
+	input := map[string]interface{}{
+		"repositoryId": info.id,
+		"title":        issue.Title,
+		"body":         issue.Body,
+	}
+
+	if len(issue.Labels) > 0 {
+		var ids []string
+		for _, label := range issue.Labels {
+			id, err := c.labelID(request.Repo, info, label)
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, id)
+		}
+		input["labelIds"] = ids
+	}
+
+	if len(issue.Assignees) > 0 {
+		var ids []string
+		for _, login := range issue.Assignees {
+			ids = append(ids, info.users[strings.ToLower(login)])
+		}
+		input["assigneeIds"] = ids
+	}
+
+	if issue.Milestone != "" {
+		input["milestoneId"] = info.milestones[issue.Milestone]
+	}
+
+	return input, nil
+}
+
+// issueRepository returns the cached IDs of a repository, first looking up the
+// repository ID and the issue's labels, assignees and milestone that have not
+// been looked up yet in a single query
+func (c *GraphQLIssueClient) issueRepository(repo string, issue *models.Issue) (*issueRepository, error) {
+	// Issue types would need their own ID lookup
+	if issue.Type != "" {
+		return nil, fmt.Errorf("issue type '%s' is not supported with GraphQL; use the REST API", issue.Type)
+	}
+
+	key := strings.ToLower(repo)
+	info, ok := c.repos[key]
+	if !ok {
+		info = &issueRepository{
+			labels:     make(map[string]string),
+			milestones: make(map[string]string),
+			users:      make(map[string]string),
+		}
+	}
+
+	var labels, users, milestones []string
+	for _, label := range issue.Labels {
+		if _, ok := info.labels[strings.ToLower(label)]; !ok && !containsFold(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+	for _, login := range issue.Assignees {
+		if _, ok := info.users[strings.ToLower(login)]; !ok && !containsFold(users, login) {
+			users = append(users, login)
+		}
+	}
+	if issue.Milestone != "" {
+		if _, ok := info.milestones[issue.Milestone]; !ok {
+			if _, err := strconv.Atoi(issue.Milestone); err != nil {
+				return nil, fmt.Errorf("milestone '%s' must be a milestone number", issue.Milestone)
+			}
+			milestones = append(milestones, issue.Milestone)
+		}
+	}
+	if info.id != "" && len(labels) == 0 && len(users) == 0 && len(milestones) == 0 {
+		return info, nil
+	}
+
+	host, fullName, err := c.forRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	if host.graphql == nil {
+		return nil, fmt.Errorf("GraphQL client is not initialized")
+	}
+	owner, name, _ := strings.Cut(fullName, "/")
+
+	declarations := []string{"$owner: String!", "$name: String!"}
+	repoFields := []string{"id"}
+	var userFields []string
+	variables := map[string]interface{}{"owner": owner, "name": name}
+	for i, label := range labels {
+		alias := fmt.Sprintf("l%d", i)
+		declarations = append(declarations, fmt.Sprintf("$%s: String!", alias))
+		repoFields = append(repoFields, fmt.Sprintf("%s: label(name: $%s) { id }", alias, alias))
+		variables[alias] = label
+	}
+	for i, milestone := range milestones {
+		alias := fmt.Sprintf("m%d", i)
+		number, _ := strconv.Atoi(milestone)
+		declarations = append(declarations, fmt.Sprintf("$%s: Int!", alias))
+		repoFields = append(repoFields, fmt.Sprintf("%s: milestone(number: $%s) { id }", alias, alias))
+		variables[alias] = number
+	}
+	for i, login := range users {
+		alias := fmt.Sprintf("u%d", i)
+		declarations = append(declarations, fmt.Sprintf("$%s: String!", alias))
+		userFields = append(userFields, fmt.Sprintf("  %s: user(login: $%s) { id }", alias, alias))
+		variables[alias] = login
+	}
+	query := fmt.Sprintf("query(%s) {\n  repository(owner: $owner, name: $name) { %s }\n%s\n}",
+		strings.Join(declarations, ", "), strings.Join(repoFields, " "), strings.Join(userFields, "\n"))
+
+	var response map[string]json.RawMessage
+	err = host.graphql.Do(query, variables, &response)
+
+	// Unknown users are reported as errors next to the data that was found
+	var gqlErr *api.GraphQLError
+	if err != nil && !errors.As(err, &gqlErr) {
+		return nil, fmt.Errorf("failed to look up %s: %v", repo, err)
+	}
+	var repository map[string]json.RawMessage
+	if data, ok := response["repository"]; ok {
+		if jsonErr := json.Unmarshal(data, &repository); jsonErr != nil {
+			return nil, jsonErr
+		}
+	}
+	if repository == nil {
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up %s: %v", repo, err)
+		}
+		return nil, fmt.Errorf("repository %s not found", repo)
+	}
+
+	// nodeID returns the ID of a looked up node, or an empty string if it was not found
+	nodeID := func(data json.RawMessage) string {
+		var node *struct {
+			ID string `json:"id"`
+		}
+		if json.Unmarshal(data, &node) != nil || node == nil {
+			return ""
+		}
+		return node.ID
+	}
+	if json.Unmarshal(repository["id"], &info.id) != nil || info.id == "" {
+		return nil, fmt.Errorf("repository %s not found", repo)
+	}
+	for i, label := range labels {
+		info.labels[strings.ToLower(label)] = nodeID(repository[fmt.Sprintf("l%d", i)])
+	}
+	for i, milestone := range milestones {
+		info.milestones[milestone] = nodeID(repository[fmt.Sprintf("m%d", i)])
+	}
+	for i, login := range users {
+		info.users[strings.ToLower(login)] = nodeID(response[fmt.Sprintf("u%d", i)])
+	}
+
+	c.repos[key] = info
+	return info, nil
+}
+
+// labelID returns the ID of a label, creating the label if it does not exist
+// yet, the same way the REST API does when an issue is created with it
+func (c *GraphQLIssueClient) labelID(repo string, info *issueRepository, label string) (string, error) {
+	if id := info.labels[strings.ToLower(label)]; id != "" {
+		return id, nil
+	}
+
+	host, fullName, err := c.forRepo(repo)
+	if err != nil {
+		return "", err
+	}
+	jsonData, err := json.Marshal(map[string]string{"name": label})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request body: %v", err)
+	}
+
+	response := &struct {
+		NodeID string `json:"node_id"`
+	}{}
+	if err := host.client.Post(fmt.Sprintf("repos/%s/labels", fullName), bytes.NewReader(jsonData), response); err != nil {
+		return "", fmt.Errorf("failed to create label '%s' in %s: %v", label, repo, err)
+	}
+
+	info.labels[strings.ToLower(label)] = response.NodeID
+	return response.NodeID, nil
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
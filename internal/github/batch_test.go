@@ -0,0 +1,216 @@
+package github
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ntsk/gh-issue-bulk-create/internal/github/githubtest"
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+// fakeGitHub serves the GraphQL lookups and mutations and the REST label
+// creation used by GraphQLIssueClient, recording what it receives
+type fakeGitHub struct {
+	// unavailable makes mutations fail with 502 Bad Gateway
+	unavailable   bool
+	lookups       int
+	mutations     int
+	labelsCreated []string
+	inputs        []map[string]interface{}
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/v3/repos/octo/api/labels" {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		f.labelsCreated = append(f.labelsCreated, body["name"])
+		fmt.Fprintf(w, `{"node_id": "LA_%s"}`, body["name"])
+		return
+	}
+
+	var request struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	json.NewDecoder(r.Body).Decode(&request)
+
+	data := map[string]interface{}{}
+	var errs []map[string]interface{}
+	if strings.HasPrefix(request.Query, "query") {
+		f.lookups++
+		repository := map[string]interface{}{"id": "R_1"}
+		for name, value := range request.Variables {
+			switch {
+			case strings.HasPrefix(name, "l"):
+				if value == "bug" {
+					repository[name] = map[string]string{"id": "LA_bug"}
+				} else {
+					repository[name] = nil
+				}
+			case strings.HasPrefix(name, "m"):
+				repository[name] = map[string]string{"id": fmt.Sprintf("MI_%v", value)}
+			case strings.HasPrefix(name, "u"):
+				if value == "alice" {
+					data[name] = map[string]string{"id": "U_alice"}
+				} else {
+					data[name] = nil
+					errs = append(errs, map[string]interface{}{"message": "Could not resolve to a User", "path": []string{name}})
+				}
+			}
+		}
+		data["repository"] = repository
+	} else {
+		f.mutations++
+		if f.unavailable {
+			http.Error(w, "Bad Gateway", http.StatusBadGateway)
+			return
+		}
+		for i := 0; ; i++ {
+			alias := fmt.Sprintf("i%d", i)
+			input, ok := request.Variables[alias].(map[string]interface{})
+			if !ok {
+				break
+			}
+			f.inputs = append(f.inputs, input)
+			if input["title"] == "fail" {
+				data[alias] = nil
+				errs = append(errs, map[string]interface{}{"message": "title is invalid", "path": []string{alias}})
+				continue
+			}
+			number := len(f.inputs)
+			data[alias] = map[string]interface{}{"issue": map[string]interface{}{
+				"id":     fmt.Sprintf("I_%d", number),
+				"number": number,
+				"url":    fmt.Sprintf("https://github.com/octo/api/issues/%d", number),
+			}}
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": data, "errors": errs})
+}
+
+// newFakeClient creates a client talking to a fake GitHub server
+func newFakeClient(t *testing.T, handler http.Handler) *Client {
+	t.Helper()
+	return WithClients(githubtest.Clients(t, handler))
+}
+
+func TestGraphQLIssueClientCreateIssues(t *testing.T) {
+	fake := &fakeGitHub{}
+	client := NewGraphQLIssueClient(newFakeClient(t, fake), 2)
+
+	requests := []IssueRequest{
+		{Repo: "octo/api", Issue: &models.Issue{Title: "First", Labels: []string{"bug", "new"}, Assignees: []string{"alice"}, Milestone: "3"}},
+		{Repo: "octo/api", Issue: &models.Issue{Title: "fail"}},
+		{Repo: "octo/api", Issue: &models.Issue{Title: "Third", Labels: []string{"bug"}}},
+	}
+
+	if errs := client.ResolveIssueIDs(requests); errs != nil {
+		t.Fatalf("Expected no resolution errors, got: %v", errs)
+	}
+	// Missing labels are only created with the issues using them
+	if len(fake.labelsCreated) != 0 {
+		t.Errorf("Expected no label to be created while resolving IDs, got %v", fake.labelsCreated)
+	}
+	results := client.CreateIssues(requests)
+
+	if results[0].Err != nil || results[0].Response.Number != 1 || results[0].Response.NodeID != "I_1" {
+		t.Errorf("Expected issue #1 to be created, got %+v", results[0])
+	}
+	if results[1].Err == nil || results[1].Err.Error() != "title is invalid" {
+		t.Errorf("Expected the second issue to fail with its own error, got %+v", results[1])
+	}
+	if results[2].Err != nil || results[2].Response.Number != 3 {
+		t.Errorf("Expected issue #3 to be created, got %+v", results[2])
+	}
+
+	// IDs are looked up once and three issues are sent in two batches
+	if fake.lookups != 1 {
+		t.Errorf("Expected 1 lookup, got %d", fake.lookups)
+	}
+	if fake.mutations != 2 {
+		t.Errorf("Expected 2 batched mutations, got %d", fake.mutations)
+	}
+	if !reflect.DeepEqual(fake.labelsCreated, []string{"new"}) {
+		t.Errorf("Expected the missing label to be created, got %v", fake.labelsCreated)
+	}
+
+	first := fake.inputs[0]
+	expected := map[string]interface{}{
+		"repositoryId": "R_1",
+		"title":        "First",
+		"body":         "",
+		"labelIds":     []interface{}{"LA_bug", "LA_new"},
+		"assigneeIds":  []interface{}{"U_alice"},
+		"milestoneId":  "MI_3",
+	}
+	if !reflect.DeepEqual(first, expected) {
+		t.Errorf("Expected input %v, got %v", expected, first)
+	}
+}
+
+func TestGraphQLIssueClientResolveErrors(t *testing.T) {
+	fake := &fakeGitHub{}
+	client := NewGraphQLIssueClient(newFakeClient(t, fake), 0)
+
+	requests := []IssueRequest{
+		{Repo: "octo/api", Issue: &models.Issue{Title: "OK", Assignees: []string{"alice"}}},
+		{Repo: "octo/api", Issue: &models.Issue{Title: "Unknown user", Assignees: []string{"ghost"}}},
+		{Repo: "octo/api", Issue: &models.Issue{Title: "Milestone title", Milestone: "v1.0"}},
+		{Repo: "octo/api", Issue: &models.Issue{Title: "Typed", Type: "Bug"}},
+	}
+
+	errs := client.ResolveIssueIDs(requests)
+	if len(errs) != 4 {
+		t.Fatalf("Expected an error slot per request, got %v", errs)
+	}
+	if errs[0] != nil {
+		t.Errorf("Expected no error for the first request, got: %v", errs[0])
+	}
+	if errs[1] == nil || !strings.Contains(errs[1].Error(), "ghost") {
+		t.Errorf("Expected unknown assignee error, got: %v", errs[1])
+	}
+	if errs[2] == nil || !strings.Contains(errs[2].Error(), "milestone number") {
+		t.Errorf("Expected milestone number error, got: %v", errs[2])
+	}
+	if errs[3] == nil || !strings.Contains(errs[3].Error(), "issue type") {
+		t.Errorf("Expected issue type error, got: %v", errs[3])
+	}
+	if fake.mutations != 0 {
+		t.Errorf("Expected nothing to be created, got %d mutations", fake.mutations)
+	}
+}
+
+func TestGraphQLIssueClientUnknownOutcome(t *testing.T) {
+	fake := &fakeGitHub{unavailable: true}
+	client := NewGraphQLIssueClient(newFakeClient(t, fake), 2)
+
+	requests := []IssueRequest{
+		{Repo: "octo/api", Issue: &models.Issue{Title: "First"}},
+		{Repo: "octo/api", Issue: &models.Issue{Title: "Second"}},
+		{Repo: "octo/api", Issue: &models.Issue{Title: "Third", Labels: []string{"new"}}},
+	}
+
+	results := client.CreateIssues(requests)
+
+	// The failed batch may have been created, and nothing is sent after it
+	for i, result := range results[:2] {
+		if !errors.Is(result.Err, ErrUnknownOutcome) || !strings.Contains(result.Err.Error(), "502") {
+			t.Errorf("Expected request %d to have an unknown outcome, got %+v", i+1, result)
+		}
+	}
+	if !errors.Is(results[2].Err, ErrNotSent) {
+		t.Errorf("Expected the third request not to be sent, got %+v", results[2])
+	}
+	if fake.mutations != 1 {
+		t.Errorf("Expected 1 mutation, got %d", fake.mutations)
+	}
+	if len(fake.labelsCreated) != 0 {
+		t.Errorf("Expected no label to be created for unsent issues, got %v", fake.labelsCreated)
+	}
+}
@@ -4,33 +4,73 @@ package github
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
 
 	"github.com/cli/go-gh/v2"
 	"github.com/cli/go-gh/v2/pkg/api"
+	ghauth "github.com/cli/go-gh/v2/pkg/auth"
+	"github.com/ntsk/gh-issue-bulk-create/internal/auth"
+	"github.com/ntsk/gh-issue-bulk-create/internal/codeowners"
 	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
 )
 
 // ClientInterface defines the interface for GitHub API operations
 type ClientInterface interface {
 	CreateIssue(issue *models.Issue, repo string) (*models.IssueResponse, error)
+	CreateIssues(requests []IssueRequest) []IssueResult
+	CreateComment(repo string, number int, body string) (*models.CommentResponse, error)
+	ApplyIssueState(issue *models.Issue, repo string, created *models.IssueResponse) error
 	GetCurrentRepository() (string, error)
 	GetRateLimit() (*models.RateLimitResponse, error)
 }
 
 // Client provides GitHub API functionality
 type Client struct {
-	client *api.RESTClient
+	client  *api.RESTClient
+	graphql *api.GraphQLClient
+	host    string
+
+	// Clients for repositories on other hosts, created on first use
+	hosts map[string]*Client
+
+	discussionRepos map[string]*discussionRepository
 }
 
-// NewClient creates a new GitHub API client
-func NewClient() (*Client, error) {
-	client, err := api.DefaultRESTClient()
+// NewClient creates a new GitHub API client for a host. An empty host uses the
+// host configured for gh (GH_HOST or github.com). Requests are authenticated
+// with tokens from the token source, or with gh's token for the host if it is nil.
+func NewClient(host string, tokens auth.TokenSource) (*Client, error) {
+	if host == "" {
+		host, _ = ghauth.DefaultHost()
+	}
+	host = ghauth.NormalizeHostname(host)
+
+	// go-gh resolves the /api/v3 path on GitHub Enterprise Server
+	opts := api.ClientOptions{Host: host}
+	if tokens != nil {
+		token, err := tokens.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get a token for %s: %v", host, err)
+		}
+		// The transport replaces the token on each request so refreshed tokens are used
+		opts.AuthToken = token
+		opts.Transport = &auth.Transport{Source: tokens}
+	}
+	client, err := api.NewRESTClient(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize GitHub API client for %s: %v", host, err)
+	}
+	graphql, err := api.NewGraphQLClient(opts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize GitHub API client: %v", err)
+		return nil, fmt.Errorf("failed to initialize GitHub GraphQL client for %s: %v", host, err)
 	}
-	return &Client{client: client}, nil
+	return &Client{client: client, graphql: graphql, host: host}, nil
 }
 
 // WithClient creates a new GitHub client with a given REST client (for testing)
@@ -38,8 +78,61 @@ func WithClient(client *api.RESTClient) *Client {
 	return &Client{client: client}
 }
 
+// WithClients creates a new GitHub client for a host with given REST and
+// GraphQL clients (for testing)
+func WithClients(host string, client *api.RESTClient, graphql *api.GraphQLClient) *Client {
+	return &Client{client: client, graphql: graphql, host: host}
+}
+
+// Host returns the host the client talks to
+func (c *Client) Host() string {
+	return c.host
+}
+
+// forRepo returns the client for the host of a repository in OWNER/REPO or
+// HOST/OWNER/REPO form, along with the repository in OWNER/REPO form
+func (c *Client) forRepo(repo string) (*Client, string, error) {
+	ref, err := models.ParseRepoRef(repo)
+	if err != nil {
+		return nil, "", err
+	}
+	client, err := c.forHost(ref.Host)
+	if err != nil {
+		return nil, "", err
+	}
+	return client, ref.FullName(), nil
+}
+
+// forHost returns the client for a host, creating it on first use. An empty
+// host or the client's own host returns the client itself.
+func (c *Client) forHost(host string) (*Client, error) {
+	if host == "" || strings.EqualFold(ghauth.NormalizeHostname(host), c.host) {
+		return c, nil
+	}
+
+	host = ghauth.NormalizeHostname(host)
+	if client, ok := c.hosts[host]; ok {
+		return client, nil
+	}
+	// Token sources are tied to the client's host, so other hosts use gh's token
+	client, err := NewClient(host, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.hosts == nil {
+		c.hosts = make(map[string]*Client)
+	}
+	c.hosts[host] = client
+	return client, nil
+}
+
 // CreateIssue creates a new GitHub issue
 func (c *Client) CreateIssue(issue *models.Issue, repo string) (*models.IssueResponse, error) {
+	host, repo, err := c.forRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+
 	// GitHub API response structure
 	response := &models.IssueResponse{}
 
@@ -54,6 +147,9 @@ func (c *Client) CreateIssue(issue *models.Issue, repo string) (*models.IssueRes
 	if issue.Milestone != "" {
 		requestBody["milestone"] = issue.Milestone
 	}
+	if issue.Type != "" {
+		requestBody["type"] = issue.Type
+	}
 
 	// Convert request body to JSON
 	jsonData, err := json.Marshal(requestBody)
@@ -63,7 +159,7 @@ func (c *Client) CreateIssue(issue *models.Issue, repo string) (*models.IssueRes
 
 	// Send POST request
 	path := fmt.Sprintf("repos/%s/issues", repo)
-	err = c.client.Post(path, bytes.NewReader(jsonData), response)
+	err = host.client.Post(path, bytes.NewReader(jsonData), response)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +167,464 @@ func (c *Client) CreateIssue(issue *models.Issue, repo string) (*models.IssueRes
 	return response, nil
 }
 
-// GetCurrentRepository gets the repository information for the current directory
+// CreateComment posts a comment on an existing issue
+func (c *Client) CreateComment(repo string, number int, body string) (*models.CommentResponse, error) {
+	host, repo, err := c.forRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.CommentResponse{}
+
+	jsonData, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %v", err)
+	}
+
+	path := fmt.Sprintf("repos/%s/issues/%d/comments", repo, number)
+	err = host.client.Post(path, bytes.NewReader(jsonData), response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// CommentResult is the outcome of posting one follow-up comment
+type CommentResult struct {
+	Response *models.CommentResponse
+	Err      error
+}
+
+// FollowUp posts the follow-up comments of a newly created issue and then
+// applies its state. Comments come first because a locked issue only accepts
+// comments from collaborators with write access. It returns the result of each
+// comment and the error applying the state, if any.
+func FollowUp(client ClientInterface, issue *models.Issue, repo string, created *models.IssueResponse) ([]CommentResult, error) {
+	comments := make([]CommentResult, len(issue.Comments))
+	for i, comment := range issue.Comments {
+		comments[i].Response, comments[i].Err = client.CreateComment(repo, created.Number, comment)
+	}
+
+	if !issue.HasStateChanges() {
+		return comments, nil
+	}
+	return comments, client.ApplyIssueState(issue, repo, created)
+}
+
+// ApplyIssueState pins, locks and closes a newly created issue as requested by its
+// front matter. Pinning is done first because GitHub only pins open issues.
+func (c *Client) ApplyIssueState(issue *models.Issue, repo string, created *models.IssueResponse) error {
+	host, repo, err := c.forRepo(repo)
+	if err != nil {
+		return err
+	}
+
+	if issue.Pinned {
+		if err := host.PinIssue(created.NodeID); err != nil {
+			return fmt.Errorf("failed to pin issue #%d: %v", created.Number, err)
+		}
+	}
+
+	if issue.Locked {
+		if err := host.LockIssue(repo, created.Number, issue.LockReason); err != nil {
+			return fmt.Errorf("failed to lock issue #%d: %v", created.Number, err)
+		}
+	}
+
+	if issue.State == "closed" {
+		if err := host.CloseIssue(repo, created.Number, issue.StateReason); err != nil {
+			return fmt.Errorf("failed to close issue #%d: %v", created.Number, err)
+		}
+	}
+
+	return nil
+}
+
+// CloseIssue closes an issue with an optional state reason
+func (c *Client) CloseIssue(repo string, number int, stateReason string) error {
+	host, repo, err := c.forRepo(repo)
+	if err != nil {
+		return err
+	}
+
+	requestBody := map[string]string{"state": "closed"}
+	if stateReason != "" {
+		requestBody["state_reason"] = stateReason
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %v", err)
+	}
+
+	path := fmt.Sprintf("repos/%s/issues/%d", repo, number)
+	return host.client.Patch(path, bytes.NewReader(jsonData), nil)
+}
+
+// LockIssue locks the conversation on an issue with an optional lock reason
+func (c *Client) LockIssue(repo string, number int, lockReason string) error {
+	host, repo, err := c.forRepo(repo)
+	if err != nil {
+		return err
+	}
+
+	requestBody := map[string]string{}
+	if lockReason != "" {
+		requestBody["lock_reason"] = lockReason
+	}
+
+	jsonData, err := json.Marshal(requestBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request body: %v", err)
+	}
+
+	path := fmt.Sprintf("repos/%s/issues/%d/lock", repo, number)
+	return host.client.Put(path, bytes.NewReader(jsonData), nil)
+}
+
+// PinIssue pins an issue to its repository using the GraphQL API
+func (c *Client) PinIssue(nodeID string) error {
+	if c.graphql == nil {
+		return fmt.Errorf("GraphQL client is not initialized")
+	}
+	if nodeID == "" {
+		return fmt.Errorf("issue node ID is required")
+	}
+
+	query := `mutation($issueId: ID!) { pinIssue(input: {issueId: $issueId}) { issue { id } } }`
+	variables := map[string]interface{}{"issueId": nodeID}
+	return c.graphql.Do(query, variables, nil)
+}
+
+// activityConnections are the connections of an issue listing who edited its
+// body, commented on it and renamed it, each with "%s" for extra arguments.
+// The field naming the user is aliased to "user" in all of them.
+var activityConnections = []string{
+	`userContentEdits(first: 100%s) { pageInfo { hasNextPage endCursor } nodes { user: editor { login } } }`,
+	`comments(first: 100%s) { pageInfo { hasNextPage endCursor } nodes { user: author { login } } }`,
+	`timelineItems(first: 100, itemTypes: [RENAMED_TITLE_EVENT]%s) { pageInfo { hasNextPage endCursor } nodes { ... on RenamedTitleEvent { user: actor { login } } } }`,
+}
+
+// activityActor is the user behind an activity node; deleted (ghost) users are null
+type activityActor struct {
+	Login string `json:"login"`
+}
+
+// login returns the login of a, or "" for a deleted user
+func (a *activityActor) login() string {
+	if a == nil {
+		return ""
+	}
+	return a.Login
+}
+
+// activityPage is one page of an activity connection
+type activityPage struct {
+	PageInfo struct {
+		HasNextPage bool   `json:"hasNextPage"`
+		EndCursor   string `json:"endCursor"`
+	} `json:"pageInfo"`
+	Nodes []struct {
+		User *activityActor `json:"user"`
+	} `json:"nodes"`
+}
+
+// GetIssueActivity gets the author of an issue and everyone who edited its body,
+// renamed it or commented on it, reading every page of each. It returns nil if
+// the issue no longer exists.
+func (c *Client) GetIssueActivity(repo string, nodeID string) (*models.IssueActivity, error) {
+	host, _, err := c.forRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	if host.graphql == nil {
+		return nil, fmt.Errorf("GraphQL client is not initialized")
+	}
+
+	query := fmt.Sprintf(`query($id: ID!) {
+  node(id: $id) {
+    ... on Issue {
+      number
+      state
+      author { login }
+      edits: %s
+      comments: %s
+      renames: %s
+    }
+  }
+}`, fmt.Sprintf(activityConnections[0], ""), fmt.Sprintf(activityConnections[1], ""), fmt.Sprintf(activityConnections[2], ""))
+	var response struct {
+		Node *struct {
+			Number   int            `json:"number"`
+			State    string         `json:"state"`
+			Author   *activityActor `json:"author"`
+			Edits    activityPage   `json:"edits"`
+			Comments activityPage   `json:"comments"`
+			Renames  activityPage   `json:"renames"`
+		} `json:"node"`
+	}
+	err = host.graphql.Do(query, map[string]interface{}{"id": nodeID}, &response)
+	// Only a node that cannot be resolved means the issue was deleted; other
+	// errors, such as missing scopes, must not be mistaken for it
+	var gqlErr *api.GraphQLError
+	if errors.As(err, &gqlErr) && response.Node == nil && gqlErr.Match("NOT_FOUND", "node") {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if response.Node == nil {
+		return nil, nil
+	}
+
+	node := response.Node
+	activity := &models.IssueActivity{Number: node.Number, State: strings.ToLower(node.State), Author: node.Author.login()}
+	lists := []*[]string{&activity.Editors, &activity.Commenters, &activity.Renamers}
+	for i, page := range []activityPage{node.Edits, node.Comments, node.Renames} {
+		logins, err := host.activityLogins(nodeID, activityConnections[i], page)
+		if err != nil {
+			return nil, err
+		}
+		*lists[i] = logins
+	}
+	return activity, nil
+}
+
+// activityLogins returns the logins of the first page of an activity
+// connection and of every page after it
+func (c *Client) activityLogins(nodeID string, connection string, page activityPage) ([]string, error) {
+	query := fmt.Sprintf(`query($id: ID!, $after: String!) {
+  node(id: $id) {
+    ... on Issue {
+      page: %s
+    }
+  }
+}`, fmt.Sprintf(connection, ", after: $after"))
+
+	var logins []string
+	for {
+		for _, node := range page.Nodes {
+			logins = append(logins, node.User.login())
+		}
+		if !page.PageInfo.HasNextPage {
+			return logins, nil
+		}
+
+		var response struct {
+			Node *struct {
+				Page activityPage `json:"page"`
+			} `json:"node"`
+		}
+		variables := map[string]interface{}{"id": nodeID, "after": page.PageInfo.EndCursor}
+		if err := c.graphql.Do(query, variables, &response); err != nil {
+			return nil, err
+		}
+		if response.Node == nil {
+			return nil, fmt.Errorf("issue %s disappeared while reading its activity", nodeID)
+		}
+		page = response.Node.Page
+	}
+}
+
+// DeleteIssue permanently deletes an issue using the GraphQL API. This requires
+// admin access to the repository.
+func (c *Client) DeleteIssue(repo string, nodeID string) error {
+	host, _, err := c.forRepo(repo)
+	if err != nil {
+		return err
+	}
+	if host.graphql == nil {
+		return fmt.Errorf("GraphQL client is not initialized")
+	}
+
+	query := `mutation($issueId: ID!) { deleteIssue(input: {issueId: $issueId}) { clientMutationId } }`
+	return host.graphql.Do(query, map[string]interface{}{"issueId": nodeID}, nil)
+}
+
+// CountOpenAssignedIssues counts the open issues assigned to a user in a repository
+func (c *Client) CountOpenAssignedIssues(repo string, user string) (int, error) {
+	host, repo, err := c.forRepo(repo)
+	if err != nil {
+		return 0, err
+	}
+
+	response := &struct {
+		TotalCount int `json:"total_count"`
+	}{}
+
+	query := fmt.Sprintf("repo:%s is:issue is:open assignee:%s", repo, user)
+	path := "search/issues?per_page=1&q=" + url.QueryEscape(query)
+	if err := host.client.Get(path, response); err != nil {
+		return 0, err
+	}
+
+	return response.TotalCount, nil
+}
+
+// GetRepository gets a repository with the viewer's permissions on it
+func (c *Client) GetRepository(repo string) (*models.Repository, error) {
+	host, repo, err := c.forRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.Repository{}
+
+	err = host.client.Get(fmt.Sprintf("repos/%s", repo), response)
+	var httpErr *api.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("not found or not accessible")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// CheckAssignee checks whether a user can be assigned to issues in a repository
+func (c *Client) CheckAssignee(repo string, user string) (bool, error) {
+	host, repo, err := c.forRepo(repo)
+	if err != nil {
+		return false, err
+	}
+
+	err = host.client.Get(fmt.Sprintf("repos/%s/assignees/%s", repo, url.PathEscape(user)), nil)
+	var httpErr *api.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ListLabels lists the names of all labels in a repository
+func (c *Client) ListLabels(repo string) ([]string, error) {
+	host, repo, err := c.forRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var labels []string
+	for page := 1; ; page++ {
+		var response []struct {
+			Name string `json:"name"`
+		}
+		path := fmt.Sprintf("repos/%s/labels?per_page=100&page=%d", repo, page)
+		if err := host.client.Get(path, &response); err != nil {
+			return nil, err
+		}
+		for _, label := range response {
+			labels = append(labels, label.Name)
+		}
+		if len(response) < 100 {
+			return labels, nil
+		}
+	}
+}
+
+// GetMilestone gets a milestone by number. It returns nil if the milestone does not exist.
+func (c *Client) GetMilestone(repo string, number int) (*models.Milestone, error) {
+	host, repo, err := c.forRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.Milestone{}
+	err = host.client.Get(fmt.Sprintf("repos/%s/milestones/%d", repo, number), response)
+	var httpErr *api.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ListTeamMembers lists the logins of all members of an organization team on a
+// host. An empty host uses the client's host.
+func (c *Client) ListTeamMembers(host string, org string, team string) ([]string, error) {
+	client, err := c.forHost(host)
+	if err != nil {
+		return nil, err
+	}
+
+	var members []string
+	for page := 1; ; page++ {
+		var response []struct {
+			Login string `json:"login"`
+		}
+		path := fmt.Sprintf("orgs/%s/teams/%s/members?per_page=100&page=%d", org, team, page)
+		if err := client.client.Get(path, &response); err != nil {
+			return nil, err
+		}
+		for _, member := range response {
+			members = append(members, member.Login)
+		}
+		if len(response) < 100 {
+			return members, nil
+		}
+	}
+}
+
+// GetCodeowners gets the content of a repository's CODEOWNERS file from the
+// locations GitHub supports. It returns an empty string if there is none.
+func (c *Client) GetCodeowners(repo string) (string, error) {
+	host, repo, err := c.forRepo(repo)
+	if err != nil {
+		return "", err
+	}
+
+	for _, location := range codeowners.Locations {
+		content, found, err := host.getFileContent(repo, location)
+		if err != nil {
+			return "", err
+		}
+		if found {
+			return content, nil
+		}
+	}
+
+	return "", nil
+}
+
+// getFileContent gets the content of a file in an OWNER/REPO repository on the
+// client's host. found is false if the file does not exist.
+func (c *Client) getFileContent(repo string, location string) (content string, found bool, err error) {
+	response := &struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}{}
+
+	path := fmt.Sprintf("repos/%s/contents/%s", repo, location)
+	err = c.client.Get(path, response)
+	var httpErr *api.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+
+	if response.Encoding != "base64" {
+		return response.Content, true, nil
+	}
+	// The API wraps base64 content across lines
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(response.Content, "\n", ""))
+	if err != nil {
+		return "", false, fmt.Errorf("failed to decode %s: %v", location, err)
+	}
+	return string(decoded), true, nil
+}
+
+// GetCurrentRepository gets the repository information for the current directory.
+// Repositories on a host other than the client's are returned in HOST/OWNER/REPO form.
 func (c *Client) GetCurrentRepository() (string, error) {
 	// RepoInfo structure to parse JSON output
 	type RepoInfo struct {
@@ -79,10 +632,11 @@ func (c *Client) GetCurrentRepository() (string, error) {
 			Login string `json:"login"`
 		} `json:"owner"`
 		Name string `json:"name"`
+		URL  string `json:"url"`
 	}
 
 	// Use gh command to get repository information
-	output, stderr, err := gh.Exec("repo", "view", "--json", "owner,name")
+	output, stderr, err := gh.Exec("repo", "view", "--json", "owner,name,url")
 	if err != nil {
 		return "", fmt.Errorf("failed to get repository information: %s - %s", err, stderr.String())
 	}
@@ -93,7 +647,11 @@ func (c *Client) GetCurrentRepository() (string, error) {
 		return "", err
 	}
 
-	return fmt.Sprintf("%s/%s", info.Owner.Login, info.Name), nil
+	repo := fmt.Sprintf("%s/%s", info.Owner.Login, info.Name)
+	if u, err := url.Parse(info.URL); err == nil && u.Host != "" && !strings.EqualFold(ghauth.NormalizeHostname(u.Host), c.host) {
+		repo = u.Host + "/" + repo
+	}
+	return repo, nil
 }
 
 // GetRateLimit gets the current GitHub API rate limit information
@@ -108,3 +666,23 @@ func (c *Client) GetRateLimit() (*models.RateLimitResponse, error) {
 
 	return response, nil
 }
+
+// GetHostRateLimit gets the rate limit of the token used for a host. An empty
+// host is the client's own host.
+func (c *Client) GetHostRateLimit(host string) (*models.RateLimitResponse, error) {
+	client, err := c.forHost(host)
+	if err != nil {
+		return nil, err
+	}
+	return client.GetRateLimit()
+}
+
+// RepoHost returns the host of a repository in OWNER/REPO or HOST/OWNER/REPO
+// form, which is the client's own host for OWNER/REPO
+func (c *Client) RepoHost(repo string) (string, error) {
+	client, _, err := c.forRepo(repo)
+	if err != nil {
+		return "", err
+	}
+	return client.host, nil
+}
@@ -1,17 +1,27 @@
 package github
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/ntsk/gh-issue-bulk-create/internal/github/githubtest"
 	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
 )
 
 // MockClient provides a mock GitHub client for testing
 type MockClient struct {
 	CreateIssueFunc       func(issue *models.Issue, repo string) (*models.IssueResponse, error)
+	CreateCommentFunc     func(repo string, number int, body string) (*models.CommentResponse, error)
+	ApplyIssueStateFunc   func(issue *models.Issue, repo string, created *models.IssueResponse) error
 	GetCurrentRepoFunc    func() (string, error)
 	GetRateLimitFunc      func() (*models.RateLimitResponse, error)
 	CreatedIssues         []*models.Issue
+	CreatedComments       []string
+	AppliedStates         []*models.Issue
 	GetCurrentRepoCounter int
 }
 
@@ -24,6 +34,33 @@ func (m *MockClient) CreateIssue(issue *models.Issue, repo string) (*models.Issu
 	return &models.IssueResponse{Number: 1, URL: "https://github.com/mock/repo/issues/1"}, nil
 }
 
+// CreateIssues implements the ClientInterface for testing
+func (m *MockClient) CreateIssues(requests []IssueRequest) []IssueResult {
+	results := make([]IssueResult, len(requests))
+	for i, request := range requests {
+		results[i].Response, results[i].Err = m.CreateIssue(request.Issue, request.Repo)
+	}
+	return results
+}
+
+// CreateComment implements the ClientInterface for testing
+func (m *MockClient) CreateComment(repo string, number int, body string) (*models.CommentResponse, error) {
+	m.CreatedComments = append(m.CreatedComments, body)
+	if m.CreateCommentFunc != nil {
+		return m.CreateCommentFunc(repo, number, body)
+	}
+	return &models.CommentResponse{ID: 1, URL: "https://github.com/mock/repo/issues/1#issuecomment-1"}, nil
+}
+
+// ApplyIssueState implements the ClientInterface for testing
+func (m *MockClient) ApplyIssueState(issue *models.Issue, repo string, created *models.IssueResponse) error {
+	m.AppliedStates = append(m.AppliedStates, issue)
+	if m.ApplyIssueStateFunc != nil {
+		return m.ApplyIssueStateFunc(issue, repo, created)
+	}
+	return nil
+}
+
 // GetCurrentRepository implements the ClientInterface for testing
 func (m *MockClient) GetCurrentRepository() (string, error) {
 	m.GetCurrentRepoCounter++
@@ -199,3 +236,271 @@ func TestRateLimit(t *testing.T) {
 		t.Errorf("Expected custom remaining 30, got %d", rateLimit.Rate.Remaining)
 	}
 }
+
+// TestCreateComment tests that CreateComment posts the body to the issue's comments
+func TestCreateComment(t *testing.T) {
+	var method, path string
+	var body map[string]string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"id": 42, "html_url": "https://github.com/octo/api/issues/7#issuecomment-42"}`)
+	})
+	client := newFakeClient(t, handler)
+
+	response, err := client.CreateComment("octo/api", 7, "First comment")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if method != http.MethodPost || path != "/api/v3/repos/octo/api/issues/7/comments" {
+		t.Errorf("Expected POST to the issue's comments, got %s %s", method, path)
+	}
+	if expected := (map[string]string{"body": "First comment"}); !reflect.DeepEqual(body, expected) {
+		t.Errorf("Expected request body %v, got %v", expected, body)
+	}
+	if response.ID != 42 || response.URL != "https://github.com/octo/api/issues/7#issuecomment-42" {
+		t.Errorf("Unexpected comment response %+v", response)
+	}
+
+	if _, err := client.CreateComment("api", 7, "Comment"); err == nil {
+		t.Error("Expected error for repository without owner, got nil")
+	}
+}
+
+// TestFollowUp tests that follow-up comments are posted before the issue is
+// pinned, locked and closed
+func TestFollowUp(t *testing.T) {
+	var requests []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch r.URL.Path {
+		case "/api/v3/repos/octo/api/issues/7/comments":
+			fmt.Fprintf(w, `{"id": %d, "html_url": "https://github.com/octo/api/issues/7#issuecomment-%d"}`, len(requests), len(requests))
+		case "/api/graphql":
+			fmt.Fprint(w, `{"data": {"pinIssue": {"issue": {"id": "I_7"}}}}`)
+		case "/api/v3/repos/octo/api/issues/7/lock":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			fmt.Fprint(w, `{}`)
+		}
+	})
+	client := newFakeClient(t, handler)
+
+	issue := &models.Issue{
+		Title:       "Migrated",
+		Comments:    []string{"First", "Second"},
+		State:       "closed",
+		StateReason: "not_planned",
+		Locked:      true,
+		Pinned:      true,
+	}
+	created := &models.IssueResponse{Number: 7, NodeID: "I_7"}
+
+	comments, err := FollowUp(client, issue, "octo/api", created)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(comments) != 2 || comments[0].Err != nil || comments[1].Response.ID != 2 {
+		t.Errorf("Unexpected comment results %+v", comments)
+	}
+
+	expected := []string{
+		"POST /api/v3/repos/octo/api/issues/7/comments",
+		"POST /api/v3/repos/octo/api/issues/7/comments",
+		"POST /api/graphql",
+		"PUT /api/v3/repos/octo/api/issues/7/lock",
+		"PATCH /api/v3/repos/octo/api/issues/7",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("Expected requests %v, got %v", expected, requests)
+	}
+}
+
+// TestFollowUpWithoutStateChanges tests that an issue without state changes
+// only gets its comments
+func TestFollowUpWithoutStateChanges(t *testing.T) {
+	mockClient := &MockClient{
+		CreateCommentFunc: func(repo string, number int, body string) (*models.CommentResponse, error) {
+			return nil, fmt.Errorf("comment failed")
+		},
+	}
+
+	comments, err := FollowUp(mockClient, &models.Issue{Comments: []string{"Only"}}, "octo/api", &models.IssueResponse{Number: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(comments) != 1 || comments[0].Err == nil {
+		t.Errorf("Expected the failed comment to be reported, got %+v", comments)
+	}
+	if len(mockClient.AppliedStates) != 0 {
+		t.Errorf("Expected no state to be applied, got %v", mockClient.AppliedStates)
+	}
+}
+
+// TestPinIssueWithoutGraphQL tests that pinning fails cleanly without a GraphQL client
+func TestPinIssueWithoutGraphQL(t *testing.T) {
+	client := WithClient(nil)
+
+	if err := client.PinIssue("I_kwDOAAAAAA"); err == nil {
+		t.Error("Expected error when GraphQL client is not initialized, got nil")
+	}
+}
+
+// TestForRepo tests that repositories are routed to the client for their host
+func TestForRepo(t *testing.T) {
+	enterprise := &Client{host: "ghe.example.com"}
+	client := &Client{host: "github.com", hosts: map[string]*Client{"ghe.example.com": enterprise}}
+
+	testCases := []struct {
+		repo         string
+		expected     *Client
+		expectedRepo string
+	}{
+		{repo: "octo/api", expected: client, expectedRepo: "octo/api"},
+		{repo: "github.com/octo/api", expected: client, expectedRepo: "octo/api"},
+		{repo: "GHE.example.com/octo/api", expected: enterprise, expectedRepo: "octo/api"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.repo, func(t *testing.T) {
+			hostClient, repo, err := client.forRepo(tc.repo)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if hostClient != tc.expected {
+				t.Errorf("Expected client for %s, got client for %s", tc.expected.host, hostClient.host)
+			}
+			if repo != tc.expectedRepo {
+				t.Errorf("Expected repository '%s', got '%s'", tc.expectedRepo, repo)
+			}
+		})
+	}
+
+	if _, _, err := client.forRepo("api"); err == nil {
+		t.Error("Expected error for repository without owner, got nil")
+	}
+}
+
+// TestGetHostRateLimit tests reading the rate limit of a repository's host
+func TestGetHostRateLimit(t *testing.T) {
+	var paths []string
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		fmt.Fprint(w, `{"rate": {"limit": 5000, "remaining": 12, "reset": 1700000000}, "resources": {"graphql": {"limit": 5000, "remaining": 7}}}`)
+	}))
+
+	for _, repo := range []string{"octo/api", client.Host() + "/octo/api"} {
+		host, err := client.RepoHost(repo)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if host != client.Host() {
+			t.Errorf("Expected host '%s' for %s, got '%s'", client.Host(), repo, host)
+		}
+	}
+	if _, err := client.RepoHost("api"); err == nil {
+		t.Error("Expected error for an invalid repository, got nil")
+	}
+
+	rateLimit, err := client.GetHostRateLimit(client.Host())
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if rateLimit.Rate.Remaining != 12 || rateLimit.Resources.GraphQL.Remaining != 7 {
+		t.Errorf("Unexpected rate limit: %+v", rateLimit)
+	}
+	if !reflect.DeepEqual(paths, []string{"/api/v3/rate_limit"}) {
+		t.Errorf("Expected one request to /api/v3/rate_limit, got %v", paths)
+	}
+}
+
+// TestGetIssueActivity tests reading who changed an issue against a fake GitHub server
+func TestGetIssueActivity(t *testing.T) {
+	client := newFakeClient(t, &githubtest.Issues{
+		Nodes: map[string]*githubtest.Issue{"I_1": {
+			Number:     1,
+			State:      "OPEN",
+			Author:     "bot",
+			Editors:    []string{"bot", "alice"},
+			Commenters: []string{"", "bob"},
+			Renamers:   []string{"carol"},
+		}},
+		Errors: map[string]string{"I_forbidden": "FORBIDDEN"},
+	})
+
+	activity, err := client.GetIssueActivity("octo/api", "I_1")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	expected := &models.IssueActivity{
+		Number:     1,
+		State:      "open",
+		Author:     "bot",
+		Editors:    []string{"bot", "alice"},
+		Renamers:   []string{"carol"},
+		Commenters: []string{"", "bob"},
+	}
+	if !reflect.DeepEqual(activity, expected) {
+		t.Errorf("Expected activity %+v, got %+v", expected, activity)
+	}
+
+	// A deleted issue has no activity
+	activity, err = client.GetIssueActivity("octo/api", "I_gone")
+	if err != nil || activity != nil {
+		t.Errorf("Expected no activity and no error for a deleted issue, got %+v (%v)", activity, err)
+	}
+
+	// Other errors are not mistaken for a deleted issue
+	_, err = client.GetIssueActivity("octo/api", "I_forbidden")
+	if err == nil || !strings.Contains(err.Error(), "Resource not accessible by integration") {
+		t.Errorf("Expected the FORBIDDEN error, got: %v", err)
+	}
+}
+
+// TestGetIssueActivityPages tests that activity beyond the first page is read
+func TestGetIssueActivityPages(t *testing.T) {
+	client := newFakeClient(t, &githubtest.Issues{
+		Nodes: map[string]*githubtest.Issue{"I_1": {
+			Number:     1,
+			State:      "CLOSED",
+			Author:     "bot",
+			Editors:    []string{"bot", "bot", "alice"},
+			Commenters: []string{"bot", "bot", "bot", "", "bob"},
+			Renamers:   []string{"bot", "carol"},
+		}},
+		PageSize: 2,
+	})
+
+	activity, err := client.GetIssueActivity("octo/api", "I_1")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	expected := &models.IssueActivity{
+		Number:     1,
+		State:      "closed",
+		Author:     "bot",
+		Editors:    []string{"bot", "bot", "alice"},
+		Renamers:   []string{"bot", "carol"},
+		Commenters: []string{"bot", "bot", "bot", "", "bob"},
+	}
+	if !reflect.DeepEqual(activity, expected) {
+		t.Errorf("Expected activity %+v, got %+v", expected, activity)
+	}
+}
+
+// TestDeleteIssue tests deleting an issue against a fake GitHub server
+func TestDeleteIssue(t *testing.T) {
+	fake := &githubtest.Issues{}
+	client := newFakeClient(t, fake)
+
+	if err := client.DeleteIssue("octo/api", "I_1"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(fake.Changes, []string{"delete I_1"}) {
+		t.Errorf("Expected issue I_1 to be deleted, got %v", fake.Changes)
+	}
+	if err := WithClient(nil).DeleteIssue("octo/api", "I_1"); err == nil {
+		t.Error("Expected error without GraphQL client, got nil")
+	}
+}
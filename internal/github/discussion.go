@@ -0,0 +1,168 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+// DiscussionClientInterface defines the interface for GitHub Discussions operations
+type DiscussionClientInterface interface {
+	GetDiscussionCategory(repo string, category string) (*models.DiscussionCategory, error)
+	CreateDiscussion(issue *models.Issue, repo string) (*models.DiscussionResponse, error)
+	CreateDiscussionComment(repo string, discussionID string, body string) (*models.CommentResponse, error)
+}
+
+// discussionRepository holds the repository ID and discussion categories needed
+// to create discussions, cached per repository
+type discussionRepository struct {
+	id         string
+	categories []models.DiscussionCategory
+}
+
+// GetDiscussionCategory resolves a discussion category by name or slug (case-insensitive)
+func (c *Client) GetDiscussionCategory(repo string, category string) (*models.DiscussionCategory, error) {
+	host, repo, err := c.forRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := host.discussionRepository(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	if category == "" {
+		return nil, fmt.Errorf("a discussion category is required")
+	}
+
+	var available []string
+	for i, candidate := range info.categories {
+		if strings.EqualFold(candidate.Name, category) || strings.EqualFold(candidate.Slug, category) {
+			return &info.categories[i], nil
+		}
+		available = append(available, candidate.Name)
+	}
+
+	return nil, fmt.Errorf("discussion category '%s' not found in %s (available: %s)", category, repo, strings.Join(available, ", "))
+}
+
+// CreateDiscussion creates a new GitHub discussion from the issue's title, body and category
+func (c *Client) CreateDiscussion(issue *models.Issue, repo string) (*models.DiscussionResponse, error) {
+	host, repo, err := c.forRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := host.discussionRepository(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	category, err := host.GetDiscussionCategory(repo, issue.Category)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `mutation($input: CreateDiscussionInput!) {
+  createDiscussion(input: $input) { discussion { id number url } }
+}`
+	variables := map[string]interface{}{
+		"input": map[string]interface{}{
+			"repositoryId": info.id,
+			"categoryId":   category.ID,
+			"title":        issue.Title,
+			"body":         issue.Body,
+		},
+	}
+
+	var response struct {
+		CreateDiscussion struct {
+			Discussion models.DiscussionResponse `json:"discussion"`
+		} `json:"createDiscussion"`
+	}
+	if err := host.graphql.Do(query, variables, &response); err != nil {
+		return nil, err
+	}
+
+	return &response.CreateDiscussion.Discussion, nil
+}
+
+// CreateDiscussionComment posts a comment on an existing discussion in a repository
+func (c *Client) CreateDiscussionComment(repo string, discussionID string, body string) (*models.CommentResponse, error) {
+	host, _, err := c.forRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+	if host.graphql == nil {
+		return nil, fmt.Errorf("GraphQL client is not initialized")
+	}
+
+	query := `mutation($discussionId: ID!, $body: String!) {
+  addDiscussionComment(input: {discussionId: $discussionId, body: $body}) { comment { html_url: url } }
+}`
+	variables := map[string]interface{}{"discussionId": discussionID, "body": body}
+
+	var response struct {
+		AddDiscussionComment struct {
+			Comment models.CommentResponse `json:"comment"`
+		} `json:"addDiscussionComment"`
+	}
+	if err := host.graphql.Do(query, variables, &response); err != nil {
+		return nil, err
+	}
+
+	return &response.AddDiscussionComment.Comment, nil
+}
+
+// discussionRepository looks up the repository ID and discussion categories,
+// querying GitHub only once per repository
+func (c *Client) discussionRepository(repo string) (*discussionRepository, error) {
+	if info, ok := c.discussionRepos[repo]; ok {
+		return info, nil
+	}
+	if c.graphql == nil {
+		return nil, fmt.Errorf("GraphQL client is not initialized")
+	}
+
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok {
+		return nil, fmt.Errorf("invalid repository '%s'", repo)
+	}
+
+	query := `query($owner: String!, $name: String!) {
+  repository(owner: $owner, name: $name) {
+    id
+    hasDiscussionsEnabled
+    discussionCategories(first: 100) { nodes { id name slug } }
+  }
+}`
+	variables := map[string]interface{}{"owner": owner, "name": name}
+
+	var response struct {
+		Repository struct {
+			ID                    string `json:"id"`
+			HasDiscussionsEnabled bool   `json:"hasDiscussionsEnabled"`
+			DiscussionCategories  struct {
+				Nodes []models.DiscussionCategory `json:"nodes"`
+			} `json:"discussionCategories"`
+		} `json:"repository"`
+	}
+	if err := c.graphql.Do(query, variables, &response); err != nil {
+		return nil, fmt.Errorf("failed to get discussion categories for %s: %v", repo, err)
+	}
+	if !response.Repository.HasDiscussionsEnabled {
+		return nil, fmt.Errorf("discussions are not enabled in %s", repo)
+	}
+
+	info := &discussionRepository{
+		id:         response.Repository.ID,
+		categories: response.Repository.DiscussionCategories.Nodes,
+	}
+	if c.discussionRepos == nil {
+		c.discussionRepos = make(map[string]*discussionRepository)
+	}
+	c.discussionRepos[repo] = info
+	return info, nil
+}
@@ -0,0 +1,181 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+// fakeDiscussions serves the GraphQL repository lookup and discussion
+// mutations, recording what it receives
+type fakeDiscussions struct {
+	enabled  bool
+	lookups  int
+	inputs   []map[string]interface{}
+	comments []map[string]interface{}
+}
+
+func (f *fakeDiscussions) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	json.NewDecoder(r.Body).Decode(&request)
+
+	var data interface{}
+	switch {
+	case strings.Contains(request.Query, "discussionCategories"):
+		f.lookups++
+		data = map[string]interface{}{"repository": map[string]interface{}{
+			"id":                    fmt.Sprintf("R_%s/%s", request.Variables["owner"], request.Variables["name"]),
+			"hasDiscussionsEnabled": f.enabled,
+			"discussionCategories": map[string]interface{}{"nodes": []map[string]string{
+				{"id": "DIC_1", "name": "Announcements", "slug": "announcements"},
+				{"id": "DIC_2", "name": "Ideas & RFCs", "slug": "ideas-rfcs"},
+			}},
+		}}
+	case strings.Contains(request.Query, "createDiscussion"):
+		f.inputs = append(f.inputs, request.Variables["input"].(map[string]interface{}))
+		number := len(f.inputs)
+		data = map[string]interface{}{"createDiscussion": map[string]interface{}{"discussion": map[string]interface{}{
+			"id": fmt.Sprintf("D_%d", number), "number": number, "url": fmt.Sprintf("https://github.com/octo/api/discussions/%d", number),
+		}}}
+	case strings.Contains(request.Query, "addDiscussionComment"):
+		// The URL is aliased to the REST field name of models.CommentResponse
+		if !strings.Contains(request.Query, "html_url: url") {
+			http.Error(w, "comment URL is not requested", http.StatusBadRequest)
+			return
+		}
+		f.comments = append(f.comments, request.Variables)
+		data = map[string]interface{}{"addDiscussionComment": map[string]interface{}{"comment": map[string]string{
+			"html_url": "https://github.com/octo/api/discussions/1#discussioncomment-1",
+		}}}
+	default:
+		http.Error(w, "unexpected query", http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+// TestCreateDiscussion tests creating discussions and comments against a fake GitHub server
+func TestCreateDiscussion(t *testing.T) {
+	fake := &fakeDiscussions{enabled: true}
+	client := newFakeClient(t, fake)
+
+	response, err := client.CreateDiscussion(&models.Issue{Title: "RFC: Caching", Body: "Proposal", Category: "ideas-rfcs"}, "octo/api")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if response.ID != "D_1" || response.Number != 1 || response.URL != "https://github.com/octo/api/discussions/1" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+
+	if _, err := client.CreateDiscussion(&models.Issue{Title: "Release", Category: "announcements"}, "octo/api"); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	// The repository is looked up once and its categories are reused
+	if fake.lookups != 1 {
+		t.Errorf("Expected 1 repository lookup, got %d", fake.lookups)
+	}
+	expected := []map[string]interface{}{
+		{"repositoryId": "R_octo/api", "categoryId": "DIC_2", "title": "RFC: Caching", "body": "Proposal"},
+		{"repositoryId": "R_octo/api", "categoryId": "DIC_1", "title": "Release", "body": ""},
+	}
+	if !reflect.DeepEqual(fake.inputs, expected) {
+		t.Errorf("Expected inputs %v, got %v", expected, fake.inputs)
+	}
+
+	// An unknown category fails before creating anything
+	_, err = client.CreateDiscussion(&models.Issue{Title: "Question", Category: "Q&A"}, "octo/api")
+	if err == nil || !strings.Contains(err.Error(), "discussion category 'Q&A' not found in octo/api (available: Announcements, Ideas & RFCs)") {
+		t.Errorf("Expected unknown category error, got: %v", err)
+	}
+	if len(fake.inputs) != 2 {
+		t.Errorf("Expected no discussion for an unknown category, got %d", len(fake.inputs))
+	}
+
+	comment, err := client.CreateDiscussionComment("octo/api", response.ID, "Feedback welcome")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if comment.URL != "https://github.com/octo/api/discussions/1#discussioncomment-1" {
+		t.Errorf("Unexpected comment URL '%s'", comment.URL)
+	}
+	if expected := []map[string]interface{}{{"discussionId": "D_1", "body": "Feedback welcome"}}; !reflect.DeepEqual(fake.comments, expected) {
+		t.Errorf("Expected comments %v, got %v", expected, fake.comments)
+	}
+}
+
+// TestCreateDiscussionDisabled tests that repositories without discussions are rejected
+func TestCreateDiscussionDisabled(t *testing.T) {
+	fake := &fakeDiscussions{}
+	client := newFakeClient(t, fake)
+
+	_, err := client.GetDiscussionCategory("octo/api", "Announcements")
+	if err == nil || !strings.Contains(err.Error(), "discussions are not enabled in octo/api") {
+		t.Errorf("Expected discussions disabled error, got: %v", err)
+	}
+	if _, err := client.CreateDiscussion(&models.Issue{Title: "RFC", Category: "Announcements"}, "octo/api"); err == nil {
+		t.Error("Expected error, got nil")
+	}
+	if len(fake.inputs) != 0 {
+		t.Errorf("Expected no discussion to be created, got %d", len(fake.inputs))
+	}
+}
+
+// TestGetDiscussionCategory tests category resolution against cached repository data
+func TestGetDiscussionCategory(t *testing.T) {
+	client := WithClient(nil)
+	client.discussionRepos = map[string]*discussionRepository{
+		"test/repo": {
+			id: "R_1",
+			categories: []models.DiscussionCategory{
+				{ID: "DIC_1", Name: "Announcements", Slug: "announcements"},
+				{ID: "DIC_2", Name: "Ideas & RFCs", Slug: "ideas-rfcs"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		category      string
+		expectedID    string
+		expectedError bool
+	}{
+		{name: "Match by name", category: "Announcements", expectedID: "DIC_1"},
+		{name: "Match by name case-insensitively", category: "ideas & rfcs", expectedID: "DIC_2"},
+		{name: "Match by slug", category: "ideas-rfcs", expectedID: "DIC_2"},
+		{name: "Unknown category", category: "Q&A", expectedError: true},
+		{name: "Empty category", category: "", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			category, err := client.GetDiscussionCategory("test/repo", tc.category)
+
+			if tc.expectedError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if category.ID != tc.expectedID {
+				t.Errorf("Expected category ID '%s', got '%s'", tc.expectedID, category.ID)
+			}
+		})
+	}
+
+	// Repositories that are not cached need the GraphQL client
+	if _, err := client.GetDiscussionCategory("other/repo", "Announcements"); err == nil {
+		t.Error("Expected error without GraphQL client, got nil")
+	}
+}
@@ -0,0 +1,167 @@
+// Package githubtest runs fake GitHub servers for tests of code using the
+// GitHub client. It does not depend on the client, so that the client's own
+// tests can use it too.
+package githubtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// Clients starts a TLS server with handler for the duration of the test and
+// returns its host with REST and GraphQL clients talking to it, in the order
+// github.WithClients takes them. REST paths start with /api/v3 and GraphQL
+// requests go to /api/graphql.
+func Clients(t *testing.T, handler http.Handler) (string, *api.RESTClient, *api.GraphQLClient) {
+	t.Helper()
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	host := server.Listener.Addr().String()
+	opts := api.ClientOptions{Host: host, AuthToken: "test", Transport: server.Client().Transport}
+	rest, err := api.NewRESTClient(opts)
+	if err != nil {
+		t.Fatalf("Failed to create REST client: %v", err)
+	}
+	graphql, err := api.NewGraphQLClient(opts)
+	if err != nil {
+		t.Fatalf("Failed to create GraphQL client: %v", err)
+	}
+	return host, rest, graphql
+}
+
+// Issue is an issue served by Issues: its state and who created and changed it
+type Issue struct {
+	Number int
+	// State is OPEN or CLOSED
+	State      string
+	Author     string
+	Editors    []string
+	Commenters []string
+	Renamers   []string
+}
+
+// Issues serves repository permissions, the activity of issues and the
+// requests that comment on, close, lock or delete them, recording every change
+type Issues struct {
+	// Admin is the admin permission reported for every repository
+	Admin bool
+	// Nodes maps node IDs to issues; missing issues are reported as deleted
+	Nodes map[string]*Issue
+	// Errors maps node IDs to the type of the GraphQL error returned for them,
+	// such as FORBIDDEN
+	Errors map[string]string
+	// PageSize is the number of edits, comments or renames served per page,
+	// 100 if zero as on GitHub
+	PageSize int
+	// Changes records the changes received, such as "delete I_1" or
+	// "PATCH /api/v3/repos/octo/api/issues/1 {...}"
+	Changes []string
+}
+
+func (f *Issues) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case r.URL.Path == "/api/graphql":
+		f.serveGraphQL(w, body)
+	case r.Method == http.MethodGet && strings.Count(r.URL.Path, "/") == 5:
+		fullName := strings.TrimPrefix(r.URL.Path, "/api/v3/repos/")
+		fmt.Fprintf(w, `{"full_name": %q, "permissions": {"admin": %t, "push": true}}`, fullName, f.Admin)
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/comments"):
+		f.Changes = append(f.Changes, fmt.Sprintf("%s %s %s", r.Method, r.URL.Path, body))
+		fmt.Fprint(w, `{"id": 1}`)
+	case r.Method == http.MethodPatch || r.Method == http.MethodPut:
+		f.Changes = append(f.Changes, fmt.Sprintf("%s %s %s", r.Method, r.URL.Path, body))
+		fmt.Fprint(w, `{}`)
+	default:
+		http.Error(w, "unexpected request", http.StatusNotFound)
+	}
+}
+
+// serveGraphQL answers issue activity lookups and deleteIssue mutations
+func (f *Issues) serveGraphQL(w http.ResponseWriter, body []byte) {
+	var request struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	json.Unmarshal(body, &request)
+
+	if strings.Contains(request.Query, "deleteIssue") {
+		f.Changes = append(f.Changes, fmt.Sprintf("delete %v", request.Variables["issueId"]))
+		fmt.Fprint(w, `{"data": {"deleteIssue": {"clientMutationId": null}}}`)
+		return
+	}
+
+	id, _ := request.Variables["id"].(string)
+	if errorType, ok := f.Errors[id]; ok {
+		fmt.Fprintf(w, `{"data": {"node": null}, "errors": [{"type": %q, "path": ["node"], "message": "Resource not accessible by integration"}]}`, errorType)
+		return
+	}
+	issue, ok := f.Nodes[id]
+	if !ok {
+		fmt.Fprintf(w, `{"data": {"node": null}, "errors": [{"type": "NOT_FOUND", "path": ["node"], "message": "Could not resolve to a node with the global id of '%s'"}]}`, id)
+		return
+	}
+
+	// Follow-up pages of a single connection
+	if after, ok := request.Variables["after"].(string); ok {
+		logins := issue.Editors
+		switch {
+		case strings.Contains(request.Query, "comments("):
+			logins = issue.Commenters
+		case strings.Contains(request.Query, "timelineItems("):
+			logins = issue.Renamers
+		}
+		start, _ := strconv.Atoi(after)
+		writeData(w, map[string]interface{}{"node": map[string]interface{}{"page": f.page(logins, start)}})
+		return
+	}
+
+	writeData(w, map[string]interface{}{"node": map[string]interface{}{
+		"number":   issue.Number,
+		"state":    issue.State,
+		"author":   user(issue.Author),
+		"edits":    f.page(issue.Editors, 0),
+		"comments": f.page(issue.Commenters, 0),
+		"renames":  f.page(issue.Renamers, 0),
+	}})
+}
+
+// page returns the page of an activity connection starting at index start,
+// using the index of the next page as its cursor
+func (f *Issues) page(logins []string, start int) map[string]interface{} {
+	size := f.PageSize
+	if size == 0 {
+		size = 100
+	}
+	end := min(start+size, len(logins))
+	nodes := make([]map[string]interface{}, 0, end-start)
+	for _, login := range logins[start:end] {
+		nodes = append(nodes, map[string]interface{}{"user": user(login)})
+	}
+	return map[string]interface{}{
+		"pageInfo": map[string]interface{}{"hasNextPage": end < len(logins), "endCursor": strconv.Itoa(end)},
+		"nodes":    nodes,
+	}
+}
+
+// writeData writes a successful GraphQL response
+func writeData(w http.ResponseWriter, data interface{}) {
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+// user returns the GraphQL actor with login, or null for a deleted (ghost) user
+func user(login string) interface{} {
+	if login == "" {
+		return nil
+	}
+	return map[string]string{"login": login}
+}
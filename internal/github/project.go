@@ -0,0 +1,206 @@
+package github
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+// ProjectClientInterface defines the interface for GitHub Projects (v2) operations
+type ProjectClientInterface interface {
+	GetProject(owner string, number int) (*models.Project, error)
+	CreateProjectDraft(project *models.Project, issue *models.Issue) (*models.ProjectItemResponse, error)
+}
+
+// ParseProjectRef parses a project reference in OWNER/NUMBER form
+func ParseProjectRef(ref string) (string, int, error) {
+	owner, numberStr, ok := strings.Cut(strings.TrimSpace(ref), "/")
+	if !ok || owner == "" {
+		return "", 0, fmt.Errorf("invalid project '%s': expected OWNER/NUMBER", ref)
+	}
+	number, err := strconv.Atoi(numberStr)
+	if err != nil || number <= 0 {
+		return "", 0, fmt.Errorf("invalid project number '%s' in '%s'", numberStr, ref)
+	}
+	return owner, number, nil
+}
+
+// GetProject looks up a user or organization project with its fields
+func (c *Client) GetProject(owner string, number int) (*models.Project, error) {
+	if c.graphql == nil {
+		return nil, fmt.Errorf("GraphQL client is not initialized")
+	}
+
+	query := `query($owner: String!, $number: Int!) {
+  repositoryOwner(login: $owner) {
+    ... on ProjectV2Owner {
+      projectV2(number: $number) {
+        id
+        title
+        fields(first: 100) {
+          nodes {
+            ... on ProjectV2FieldCommon { id name dataType }
+            ... on ProjectV2SingleSelectField { options { id name } }
+            ... on ProjectV2IterationField { configuration { iterations { id title } } }
+          }
+        }
+      }
+    }
+  }
+}`
+	variables := map[string]interface{}{"owner": owner, "number": number}
+
+	var response struct {
+		RepositoryOwner *struct {
+			ProjectV2 *struct {
+				ID     string `json:"id"`
+				Title  string `json:"title"`
+				Fields struct {
+					Nodes []struct {
+						ID       string                      `json:"id"`
+						Name     string                      `json:"name"`
+						DataType string                      `json:"dataType"`
+						Options  []models.ProjectFieldOption `json:"options"`
+						Config   struct {
+							Iterations []struct {
+								ID    string `json:"id"`
+								Title string `json:"title"`
+							} `json:"iterations"`
+						} `json:"configuration"`
+					} `json:"nodes"`
+				} `json:"fields"`
+			} `json:"projectV2"`
+		} `json:"repositoryOwner"`
+	}
+	if err := c.graphql.Do(query, variables, &response); err != nil {
+		return nil, fmt.Errorf("failed to get project %s/%d: %v", owner, number, err)
+	}
+	if response.RepositoryOwner == nil || response.RepositoryOwner.ProjectV2 == nil {
+		return nil, fmt.Errorf("project %s/%d not found", owner, number)
+	}
+
+	data := response.RepositoryOwner.ProjectV2
+	project := &models.Project{ID: data.ID, Title: data.Title}
+	for _, node := range data.Fields.Nodes {
+		field := models.ProjectField{ID: node.ID, Name: node.Name, DataType: node.DataType, Options: node.Options}
+		for _, iteration := range node.Config.Iterations {
+			field.Options = append(field.Options, models.ProjectFieldOption{ID: iteration.ID, Name: iteration.Title})
+		}
+		project.Fields = append(project.Fields, field)
+	}
+
+	return project, nil
+}
+
+// CreateProjectDraft adds a draft issue to a project and sets its field values
+func (c *Client) CreateProjectDraft(project *models.Project, issue *models.Issue) (*models.ProjectItemResponse, error) {
+	if c.graphql == nil {
+		return nil, fmt.Errorf("GraphQL client is not initialized")
+	}
+
+	// Resolve every field value before creating anything
+	values, err := ProjectFieldValues(project, issue.Fields)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `mutation($projectId: ID!, $title: String!, $body: String) {
+  addProjectV2DraftIssue(input: {projectId: $projectId, title: $title, body: $body}) { projectItem { id } }
+}`
+	variables := map[string]interface{}{"projectId": project.ID, "title": issue.Title, "body": issue.Body}
+
+	var response struct {
+		AddProjectV2DraftIssue struct {
+			ProjectItem models.ProjectItemResponse `json:"projectItem"`
+		} `json:"addProjectV2DraftIssue"`
+	}
+	if err := c.graphql.Do(query, variables, &response); err != nil {
+		return nil, err
+	}
+	item := &response.AddProjectV2DraftIssue.ProjectItem
+
+	for _, value := range values {
+		query := `mutation($projectId: ID!, $itemId: ID!, $fieldId: ID!, $value: ProjectV2FieldValue!) {
+  updateProjectV2ItemFieldValue(input: {projectId: $projectId, itemId: $itemId, fieldId: $fieldId, value: $value}) { projectV2Item { id } }
+}`
+		variables := map[string]interface{}{
+			"projectId": project.ID,
+			"itemId":    item.ID,
+			"fieldId":   value.FieldID,
+			"value":     value.Value,
+		}
+		if err := c.graphql.Do(query, variables, nil); err != nil {
+			return item, fmt.Errorf("failed to set field '%s': %v", value.FieldName, err)
+		}
+	}
+
+	return item, nil
+}
+
+// ProjectFieldValue is a resolved value for a single project field
+type ProjectFieldValue struct {
+	FieldID   string
+	FieldName string
+	Value     map[string]interface{}
+}
+
+// ProjectFieldValues converts front matter field values into project field
+// value inputs, matching field and option names case-insensitively.
+// Empty values are skipped.
+func ProjectFieldValues(project *models.Project, fields map[string]string) ([]ProjectFieldValue, error) {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var values []ProjectFieldValue
+	for _, name := range names {
+		raw := strings.TrimSpace(fields[name])
+		if raw == "" {
+			continue
+		}
+
+		field := project.Field(name)
+		if field == nil {
+			return nil, fmt.Errorf("project field '%s' not found in '%s'", name, project.Title)
+		}
+
+		var value map[string]interface{}
+		switch field.DataType {
+		case "TEXT":
+			value = map[string]interface{}{"text": raw}
+		case "NUMBER":
+			number, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return nil, fmt.Errorf("project field '%s' expects a number, got '%s'", field.Name, raw)
+			}
+			value = map[string]interface{}{"number": number}
+		case "DATE":
+			if _, err := time.Parse("2006-01-02", raw); err != nil {
+				return nil, fmt.Errorf("project field '%s' expects a date (YYYY-MM-DD), got '%s'", field.Name, raw)
+			}
+			value = map[string]interface{}{"date": raw}
+		case "SINGLE_SELECT", "ITERATION":
+			option := field.Option(raw)
+			if option == nil {
+				return nil, fmt.Errorf("project field '%s' has no option '%s'", field.Name, raw)
+			}
+			if field.DataType == "SINGLE_SELECT" {
+				value = map[string]interface{}{"singleSelectOptionId": option.ID}
+			} else {
+				value = map[string]interface{}{"iterationId": option.ID}
+			}
+		default:
+			return nil, fmt.Errorf("project field '%s' of type %s cannot be set", field.Name, field.DataType)
+		}
+
+		values = append(values, ProjectFieldValue{FieldID: field.ID, FieldName: field.Name, Value: value})
+	}
+
+	return values, nil
+}
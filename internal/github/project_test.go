@@ -0,0 +1,112 @@
+package github
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+func TestParseProjectRef(t *testing.T) {
+	testCases := []struct {
+		name           string
+		ref            string
+		expectedOwner  string
+		expectedNumber int
+		expectedError  bool
+	}{
+		{name: "Valid reference", ref: "octo-org/5", expectedOwner: "octo-org", expectedNumber: 5},
+		{name: "Missing number", ref: "octo-org", expectedError: true},
+		{name: "Non-numeric number", ref: "octo-org/roadmap", expectedError: true},
+		{name: "Zero number", ref: "octo-org/0", expectedError: true},
+		{name: "Empty owner", ref: "/5", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			owner, number, err := ParseProjectRef(tc.ref)
+
+			if tc.expectedError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if owner != tc.expectedOwner || number != tc.expectedNumber {
+				t.Errorf("Expected %s/%d, got %s/%d", tc.expectedOwner, tc.expectedNumber, owner, number)
+			}
+		})
+	}
+}
+
+func TestProjectFieldValues(t *testing.T) {
+	project := &models.Project{
+		ID:    "PVT_1",
+		Title: "Roadmap",
+		Fields: []models.ProjectField{
+			{ID: "F_text", Name: "Notes", DataType: "TEXT"},
+			{ID: "F_number", Name: "Estimate", DataType: "NUMBER"},
+			{ID: "F_date", Name: "Due", DataType: "DATE"},
+			{ID: "F_status", Name: "Status", DataType: "SINGLE_SELECT", Options: []models.ProjectFieldOption{{ID: "O_todo", Name: "Todo"}}},
+			{ID: "F_iteration", Name: "Sprint", DataType: "ITERATION", Options: []models.ProjectFieldOption{{ID: "I_1", Name: "Sprint 1"}}},
+			{ID: "F_assignees", Name: "Assignees", DataType: "ASSIGNEES"},
+		},
+	}
+
+	testCases := []struct {
+		name          string
+		fields        map[string]string
+		expected      []ProjectFieldValue
+		expectedError bool
+	}{
+		{
+			name: "All supported types",
+			fields: map[string]string{
+				"notes":    "Needs design",
+				"Estimate": "3",
+				"Due":      "2024-06-30",
+				"Status":   "todo",
+				"Sprint":   "Sprint 1",
+			},
+			expected: []ProjectFieldValue{
+				{FieldID: "F_date", FieldName: "Due", Value: map[string]interface{}{"date": "2024-06-30"}},
+				{FieldID: "F_number", FieldName: "Estimate", Value: map[string]interface{}{"number": 3.0}},
+				{FieldID: "F_iteration", FieldName: "Sprint", Value: map[string]interface{}{"iterationId": "I_1"}},
+				{FieldID: "F_status", FieldName: "Status", Value: map[string]interface{}{"singleSelectOptionId": "O_todo"}},
+				{FieldID: "F_text", FieldName: "Notes", Value: map[string]interface{}{"text": "Needs design"}},
+			},
+		},
+		{
+			name:     "Empty values are skipped",
+			fields:   map[string]string{"Status": "", "Unknown": " "},
+			expected: nil,
+		},
+		{name: "Unknown field", fields: map[string]string{"Priority": "High"}, expectedError: true},
+		{name: "Invalid number", fields: map[string]string{"Estimate": "three"}, expectedError: true},
+		{name: "Invalid date", fields: map[string]string{"Due": "30/06/2024"}, expectedError: true},
+		{name: "Unknown option", fields: map[string]string{"Status": "Blocked"}, expectedError: true},
+		{name: "Unsupported type", fields: map[string]string{"Assignees": "octocat"}, expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := ProjectFieldValues(project, tc.fields)
+
+			if tc.expectedError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(values, tc.expected) {
+				t.Errorf("Expected values %v, got %v", tc.expected, values)
+			}
+		})
+	}
+}
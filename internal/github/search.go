@@ -0,0 +1,96 @@
+package github
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+// maxSearchResults is the number of results the search API returns at most per query
+const maxSearchResults = 1000
+
+// IssueFilter selects existing issues of a repository
+type IssueFilter struct {
+	Repo      string
+	Query     string
+	State     string
+	Labels    []string
+	Milestone string
+	Assignee  string
+}
+
+// SearchQuery builds the search API query for the filter, without the repository's host
+func (f IssueFilter) SearchQuery() (string, error) {
+	ref, err := models.ParseRepoRef(f.Repo)
+	if err != nil {
+		return "", err
+	}
+
+	terms := []string{"repo:" + ref.FullName(), "is:issue"}
+	switch f.State {
+	case "", "all":
+	case "open", "closed":
+		terms = append(terms, "state:"+f.State)
+	default:
+		return "", fmt.Errorf("invalid state '%s': must be 'open', 'closed' or 'all'", f.State)
+	}
+	for _, label := range f.Labels {
+		terms = append(terms, "label:"+quoteSearchTerm(label))
+	}
+	if f.Milestone != "" {
+		terms = append(terms, "milestone:"+quoteSearchTerm(f.Milestone))
+	}
+	if f.Assignee != "" {
+		terms = append(terms, "assignee:"+f.Assignee)
+	}
+	if query := strings.TrimSpace(f.Query); query != "" {
+		terms = append(terms, query)
+	}
+
+	return strings.Join(terms, " "), nil
+}
+
+// quoteSearchTerm quotes a search qualifier value that contains spaces
+func quoteSearchTerm(value string) string {
+	if strings.ContainsAny(value, " \t") {
+		return `"` + value + `"`
+	}
+	return value
+}
+
+// SearchIssues returns the issues matching a filter, oldest first. The search
+// API returns at most 1000 issues per query, so a filter matching more fails
+// rather than returning part of the issues.
+func (c *Client) SearchIssues(filter IssueFilter) ([]models.IssueDetails, error) {
+	query, err := filter.SearchQuery()
+	if err != nil {
+		return nil, err
+	}
+	host, _, err := c.forRepo(filter.Repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []models.IssueDetails
+	for page := 1; len(issues) < maxSearchResults; page++ {
+		var response struct {
+			TotalCount int                   `json:"total_count"`
+			Items      []models.IssueDetails `json:"items"`
+		}
+		path := fmt.Sprintf("search/issues?q=%s&sort=created&order=asc&per_page=100&page=%d", url.QueryEscape(query), page)
+		if err := host.client.Get(path, &response); err != nil {
+			return nil, err
+		}
+		if response.TotalCount > maxSearchResults {
+			return nil, fmt.Errorf("%d issues match, but the search API returns at most %d; narrow the filters, for example with --state, --label or a created: range in --search", response.TotalCount, maxSearchResults)
+		}
+		issues = append(issues, response.Items...)
+		if len(response.Items) < 100 || len(issues) >= response.TotalCount {
+			break
+		}
+	}
+
+	return issues, nil
+}
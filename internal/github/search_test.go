@@ -0,0 +1,99 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestIssueFilterSearchQuery(t *testing.T) {
+	testCases := []struct {
+		name          string
+		filter        IssueFilter
+		expected      string
+		expectedError bool
+	}{
+		{
+			name:     "Repository only",
+			filter:   IssueFilter{Repo: "octo/api"},
+			expected: "repo:octo/api is:issue",
+		},
+		{
+			name: "All filters",
+			filter: IssueFilter{
+				Repo:      "ghe.example.com/octo/api",
+				Query:     "login error",
+				State:     "open",
+				Labels:    []string{"bug", "good first issue"},
+				Milestone: "v1.0",
+				Assignee:  "alice",
+			},
+			expected: `repo:octo/api is:issue state:open label:bug label:"good first issue" milestone:v1.0 assignee:alice login error`,
+		},
+		{
+			name:          "Invalid state",
+			filter:        IssueFilter{Repo: "octo/api", State: "merged"},
+			expectedError: true,
+		},
+		{
+			name:          "Invalid repository",
+			filter:        IssueFilter{Repo: "api"},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.filter.SearchQuery()
+
+			if tc.expectedError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if query != tc.expected {
+				t.Errorf("Expected query '%s', got '%s'", tc.expected, query)
+			}
+		})
+	}
+}
+
+// TestSearchIssues tests paging through search results against a fake GitHub server
+func TestSearchIssues(t *testing.T) {
+	total := 150
+	var queries []string
+	client := newFakeClient(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query().Get("q")+" page "+r.URL.Query().Get("page"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		var items []string
+		for n := (page-1)*100 + 1; n <= min(page*100, total); n++ {
+			items = append(items, fmt.Sprintf(`{"number": %d}`, n))
+		}
+		fmt.Fprintf(w, `{"total_count": %d, "items": [%s]}`, total, strings.Join(items, ","))
+	}))
+
+	issues, err := client.SearchIssues(IssueFilter{Repo: "octo/api", State: "open"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(issues) != 150 || issues[149].Number != 150 {
+		t.Errorf("Expected 150 issues, got %d", len(issues))
+	}
+	if expected := []string{"repo:octo/api is:issue state:open page 1", "repo:octo/api is:issue state:open page 2"}; !reflect.DeepEqual(queries, expected) {
+		t.Errorf("Expected queries %v, got %v", expected, queries)
+	}
+
+	// More matches than the search API returns fail instead of being cut off
+	total = 1001
+	_, err = client.SearchIssues(IssueFilter{Repo: "octo/api"})
+	if err == nil || !strings.Contains(err.Error(), "1001 issues match, but the search API returns at most 1000") {
+		t.Errorf("Expected an error for too many matches, got: %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package github
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/ntsk/gh-issue-bulk-create/internal/template"
+)
+
+// TemplateCatalog reads the issue templates of a repository through the contents API
+type TemplateCatalog struct {
+	client *Client
+	repo   string
+}
+
+// NewTemplateCatalog creates a catalog of the issue templates in repo
+func NewTemplateCatalog(client *Client, repo string) *TemplateCatalog {
+	return &TemplateCatalog{client: client, repo: repo}
+}
+
+// ListTemplates implements template.Catalog. A repository without a template
+// directory has no templates.
+func (c *TemplateCatalog) ListTemplates() ([]string, error) {
+	host, repo, err := c.client.forRepo(c.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []struct {
+		Name string `json:"name"`
+		Type string `json:"type"`
+	}
+	path := fmt.Sprintf("repos/%s/contents/%s", repo, template.TemplateDir)
+	err = host.client.Get(path, &entries)
+	var httpErr *api.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to list issue templates of %s: %v", c.repo, err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.Type == "file" {
+			names = append(names, entry.Name)
+		}
+	}
+	return template.TemplateFiles(names), nil
+}
+
+// ReadTemplate implements template.Catalog
+func (c *TemplateCatalog) ReadTemplate(file string) ([]byte, error) {
+	host, repo, err := c.client.forRepo(c.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	content, found, err := host.getFileContent(repo, template.TemplateDir+"/"+file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read issue template %s of %s: %v", file, c.repo, err)
+	}
+	if !found {
+		return nil, fmt.Errorf("issue template %s not found in %s", file, c.repo)
+	}
+	return []byte(content), nil
+}
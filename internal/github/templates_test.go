@@ -0,0 +1,55 @@
+package github
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestTemplateCatalog(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/octo/api/contents/.github/ISSUE_TEMPLATE":
+			fmt.Fprint(w, `[
+				{"name": "bug_report.yml", "type": "file"},
+				{"name": "config.yml", "type": "file"},
+				{"name": "archive", "type": "dir"},
+				{"name": "feature.md", "type": "file"}
+			]`)
+		case "/api/v3/repos/octo/api/contents/.github/ISSUE_TEMPLATE/feature.md":
+			fmt.Fprintf(w, `{"encoding": "base64", "content": %q}`, base64.StdEncoding.EncodeToString([]byte("---\ntitle: Feature\n---\nBody")))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	client := newFakeClient(t, handler)
+
+	catalog := NewTemplateCatalog(client, "octo/api")
+	files, err := catalog.ListTemplates()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if expected := []string{"bug_report.yml", "feature.md"}; !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected templates %v, got %v", expected, files)
+	}
+
+	content, err := catalog.ReadTemplate("feature.md")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if string(content) != "---\ntitle: Feature\n---\nBody" {
+		t.Errorf("Unexpected template content %q", content)
+	}
+
+	if _, err := catalog.ReadTemplate("missing.md"); err == nil {
+		t.Error("Expected error for missing template, got nil")
+	}
+
+	// A repository without a template directory has no templates
+	files, err = NewTemplateCatalog(client, "octo/web").ListTemplates()
+	if err != nil || len(files) != 0 {
+		t.Errorf("Expected no templates and no error, got %v (%v)", files, err)
+	}
+}
@@ -0,0 +1,190 @@
+package input
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ntsk/gh-issue-bulk-create/internal/csv"
+	"github.com/ntsk/gh-issue-bulk-create/internal/template"
+)
+
+// TemplateColumn is the CSV column naming the template of each row with --template-dir
+const TemplateColumn = "template"
+
+// Source is the data a single template rendering is built from: a CSV row, or
+// a group of rows when grouping by a column
+type Source struct {
+	Label string
+	Data  map[string]string
+	Rows  []map[string]string
+	// Indexes holds the 1-based positions of the source's rows in the CSV
+	Indexes  []int
+	Template *Template
+}
+
+// Render renders the template with the source data and any extra variables
+// (such as a matrix combination) on top of it
+func (s *Source) Render(renderer *template.Renderer, tmplContent string, extra map[string]string) (string, error) {
+	data := s.Data
+	if len(extra) > 0 {
+		data = make(map[string]string, len(s.Data)+len(extra))
+		for key, value := range s.Data {
+			data[key] = value
+		}
+		for key, value := range extra {
+			data[key] = value
+		}
+	}
+
+	if s.Rows != nil {
+		return renderer.RenderGroup(tmplContent, data, s.Rows)
+	}
+	return renderer.Render(tmplContent, data)
+}
+
+// BuildSources builds one source per row, or one per group of rows when
+// groupBy names a column. rowNumbers holds the number of each row in the CSV,
+// and listColumns the columns whose cells are lists when grouping.
+func BuildSources(csvParser *csv.Parser, dataMaps []map[string]string, rowNumbers []int, groupBy string, listColumns []string) []*Source {
+	var sources []*Source
+	if groupBy == "" {
+		for i, data := range dataMaps {
+			sources = append(sources, &Source{Label: fmt.Sprintf("row %d", rowNumbers[i]), Data: data, Indexes: []int{rowNumbers[i]}})
+		}
+		return sources
+	}
+
+	for _, group := range csvParser.GroupRecords(dataMaps, groupBy) {
+		// Group indexes count the given rows; refer to the CSV instead
+		for i, index := range group.Indexes {
+			group.Indexes[i] = rowNumbers[index-1]
+		}
+		sources = append(sources, &Source{
+			Label:   fmt.Sprintf("group '%s' (rows %s)", group.Key, FormatRowIndexes(group.Indexes)),
+			Data:    group.Data(listColumns),
+			Rows:    group.Rows,
+			Indexes: group.Indexes,
+		})
+	}
+	return sources
+}
+
+// AssignTemplates gives each source the template named in its template
+// column, loaded from dir, or defaultTmpl when the column is empty. It returns
+// the sources with a template and a failure for each of the others, such as
+// "load template for row 3: ...".
+func AssignTemplates(sources []*Source, dir *TemplateDir, defaultTmpl *Template) ([]*Source, []string) {
+	var assigned []*Source
+	var failures []string
+	for _, source := range sources {
+		name := strings.TrimSpace(source.Data[TemplateColumn])
+		if name == "" {
+			if defaultTmpl == nil {
+				failures = append(failures, fmt.Sprintf("choose a template for %s: the '%s' column is empty and there is no default --template", source.Label, TemplateColumn))
+				continue
+			}
+			source.Template = defaultTmpl
+		} else {
+			tmpl, err := dir.Load(name)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("load template for %s: %v", source.Label, err))
+				continue
+			}
+			source.Template = tmpl
+		}
+		assigned = append(assigned, source)
+	}
+	return assigned, failures
+}
+
+// CheckHeaders checks the CSV headers against the variables of the templates
+// the sources use and returns warnings. readColumns are used by other means,
+// such as a mapping or a row filter. grouped is set when rows are grouped, so
+// that aggregated variables refer to the columns they aggregate.
+//
+// With perRowTemplates, rows choose their template in the template column:
+// missing variables are reported per template along with the rows that use
+// it, and a header is only unused when no template uses it.
+func CheckHeaders(csvParser *csv.Parser, headers []string, readColumns []string, sources []*Source, grouped bool, perRowTemplates bool) ([]string, error) {
+	// Collect the templates in use in order of first use, with their rows
+	var used []*Template
+	rows := make(map[*Template][]int)
+	for _, source := range sources {
+		if _, ok := rows[source.Template]; !ok {
+			used = append(used, source.Template)
+		}
+		rows[source.Template] = append(rows[source.Template], source.Indexes...)
+	}
+
+	templateVars := func(tmpl *Template) []string {
+		vars := tmpl.Vars
+		if grouped {
+			vars = GroupVariableColumns(vars)
+		}
+		return append(append([]string{}, vars...), readColumns...)
+	}
+
+	if !perRowTemplates {
+		if len(used) == 0 {
+			return nil, nil
+		}
+		check, err := csvParser.CompareHeaders(headers, templateVars(used[0]))
+		if err != nil {
+			return nil, err
+		}
+		return check.Warnings(), nil
+	}
+
+	union := []string{TemplateColumn}
+	for _, tmpl := range used {
+		for _, v := range templateVars(tmpl) {
+			if !slices.Contains(union, v) {
+				union = append(union, v)
+			}
+		}
+	}
+	check, err := csvParser.CompareHeaders(headers, union)
+	if err != nil {
+		return nil, err
+	}
+	warnings := (&csv.HeaderCheck{Unused: check.Unused}).Warnings()
+
+	for _, tmpl := range used {
+		check, err := csvParser.CompareHeaders(headers, templateVars(tmpl))
+		if err != nil {
+			return nil, err
+		}
+		for _, warning := range (&csv.HeaderCheck{Missing: check.Missing}).Warnings() {
+			warnings = append(warnings, fmt.Sprintf("%s (template %s, rows %s)", warning, tmpl.File, FormatRowIndexes(rows[tmpl])))
+		}
+	}
+	return warnings, nil
+}
+
+// GroupVariableColumns maps group variables such as "rows.labels" to the
+// columns they aggregate, dropping "rows.count"
+func GroupVariableColumns(templateVars []string) []string {
+	seen := make(map[string]bool)
+	var columns []string
+	for _, v := range templateVars {
+		if v == "rows.count" {
+			continue
+		}
+		v = strings.TrimPrefix(v, "rows.")
+		if !seen[v] {
+			seen[v] = true
+			columns = append(columns, v)
+		}
+	}
+	return columns
+}
+
+// FormatRowIndexes formats 1-based row numbers as a comma-separated list
+func FormatRowIndexes(indexes []int) string {
+	parts := make([]string, len(indexes))
+	for i, index := range indexes {
+		parts[i] = fmt.Sprintf("%d", index)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,169 @@
+package input
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ntsk/gh-issue-bulk-create/internal/csv"
+	"github.com/ntsk/gh-issue-bulk-create/internal/template"
+)
+
+func TestBuildSources(t *testing.T) {
+	parser := csv.NewParser()
+	dataMaps := []map[string]string{
+		{"team": "api", "title": "One", "labels": "bug"},
+		{"team": "web", "title": "Two", "labels": "ui"},
+		{"team": "api", "title": "Three", "labels": "docs"},
+	}
+	// The selected rows are rows 2, 4 and 5 of the CSV
+	rowNumbers := []int{2, 4, 5}
+
+	sources := BuildSources(parser, dataMaps, rowNumbers, "", nil)
+	var labels []string
+	for _, source := range sources {
+		labels = append(labels, source.Label)
+	}
+	if expected := []string{"row 2", "row 4", "row 5"}; !reflect.DeepEqual(labels, expected) {
+		t.Errorf("Expected labels %v, got %v", expected, labels)
+	}
+
+	sources = BuildSources(parser, dataMaps, rowNumbers, "team", csv.DefaultListColumns)
+	if len(sources) != 2 {
+		t.Fatalf("Expected 2 groups, got %d", len(sources))
+	}
+	api := sources[0]
+	if api.Label != "group 'api' (rows 2, 5)" {
+		t.Errorf("Expected the api group to refer to CSV rows, got %s", api.Label)
+	}
+	if !reflect.DeepEqual(api.Indexes, []int{2, 5}) {
+		t.Errorf("Expected indexes [2 5], got %v", api.Indexes)
+	}
+	if len(api.Rows) != 2 || api.Data["rows.count"] != "2" || api.Data["rows.labels"] != "bug, docs" {
+		t.Errorf("Expected the group to hold both rows and aggregate labels, got %+v", api)
+	}
+}
+
+func TestSourceRender(t *testing.T) {
+	renderer := template.NewRenderer()
+	source := &Source{Data: map[string]string{"name": "api", "env": "dev"}}
+
+	rendered, err := source.Render(renderer, "{{name}} on {{env}}", map[string]string{"env": "production"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if rendered != "api on production" {
+		t.Errorf("Expected extra variables to override the row, got %q", rendered)
+	}
+	if source.Data["env"] != "dev" {
+		t.Error("Expected the source data to be left unchanged")
+	}
+}
+
+func TestAssignTemplates(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"bug.md": "---\ntitle: {{title}}\n---\nBody",
+	})
+	templates := NewTemplateDir(dir, NewLoader(nil, template.NewRenderer(), template.NewParser()))
+	defaultTmpl := &Template{File: "default.md"}
+	newSources := func() []*Source {
+		return []*Source{
+			{Label: "row 2", Data: map[string]string{"template": "bug"}},
+			{Label: "row 3", Data: map[string]string{"template": " "}},
+			{Label: "row 4", Data: map[string]string{"template": "missing"}},
+		}
+	}
+
+	assigned, failures := AssignTemplates(newSources(), templates, defaultTmpl)
+	if len(assigned) != 2 || assigned[0].Template.File != "bug.md" || assigned[1].Template != defaultTmpl {
+		t.Errorf("Expected rows 2 and 3 to use bug.md and the default template, got %+v", assigned)
+	}
+	if len(failures) != 1 || !strings.HasPrefix(failures[0], "load template for row 4: ") {
+		t.Errorf("Expected a failure for row 4, got %v", failures)
+	}
+
+	// Without a default template, rows with an empty column fail too
+	assigned, failures = AssignTemplates(newSources(), templates, nil)
+	if len(assigned) != 1 {
+		t.Errorf("Expected only row 2 to be assigned, got %+v", assigned)
+	}
+	expected := "choose a template for row 3: the 'template' column is empty and there is no default --template"
+	if len(failures) != 2 || failures[0] != expected {
+		t.Errorf("Expected failures for rows 3 and 4, got %v", failures)
+	}
+}
+
+func TestCheckHeaders(t *testing.T) {
+	parser := csv.NewParser()
+	bug := &Template{File: "bug.md", Vars: []string{"title", "severity"}}
+	task := &Template{File: "task.md", Vars: []string{"title", "rows.assignee", "rows.count"}}
+
+	testCases := []struct {
+		name            string
+		headers         []string
+		readColumns     []string
+		sources         []*Source
+		grouped         bool
+		perRowTemplates bool
+		expected        []string
+	}{
+		{
+			name:     "Single template",
+			headers:  []string{"title", "notes"},
+			sources:  []*Source{{Indexes: []int{2}, Template: bug}},
+			expected: []string{"Warning: The following CSV headers are not used in the template: notes", "Warning: The following template variables are missing from CSV headers: severity"},
+		},
+		{
+			name:        "Columns read by other means are used",
+			headers:     []string{"title", "severity", "status"},
+			readColumns: []string{"status"},
+			sources:     []*Source{{Indexes: []int{2}, Template: bug}},
+		},
+		{
+			name:     "Group variables refer to columns",
+			headers:  []string{"title", "assignee"},
+			sources:  []*Source{{Indexes: []int{2, 3}, Template: task}},
+			grouped:  true,
+			expected: nil,
+		},
+		{
+			name:    "Per-row templates",
+			headers: []string{"template", "title", "assignee", "notes"},
+			sources: []*Source{
+				{Indexes: []int{2}, Template: bug},
+				{Indexes: []int{3}, Template: task},
+				{Indexes: []int{4}, Template: bug},
+			},
+			grouped:         true,
+			perRowTemplates: true,
+			expected: []string{
+				"Warning: The following CSV headers are not used in the template: notes",
+				"Warning: The following template variables are missing from CSV headers: severity (template bug.md, rows 2, 4)",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			warnings, err := CheckHeaders(parser, tc.headers, tc.readColumns, tc.sources, tc.grouped, tc.perRowTemplates)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(warnings, tc.expected) {
+				t.Errorf("Expected warnings %q, got %q", tc.expected, warnings)
+			}
+		})
+	}
+}
+
+func TestFormatRowIndexes(t *testing.T) {
+	if formatted := FormatRowIndexes([]int{2, 5, 9}); formatted != "2, 5, 9" {
+		t.Errorf("Expected '2, 5, 9', got %q", formatted)
+	}
+}
+
+func TestGroupVariableColumns(t *testing.T) {
+	if columns := GroupVariableColumns([]string{"rows.title", "rows.count", "title", "team"}); !reflect.DeepEqual(columns, []string{"title", "team"}) {
+		t.Errorf("Expected columns [title team], got %v", columns)
+	}
+}
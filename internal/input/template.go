@@ -0,0 +1,180 @@
+// Package input builds what issues are rendered from: the templates, read
+// from a file or a template directory, and a source per CSV row or group of
+// rows along with the template it uses.
+package input
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ntsk/gh-issue-bulk-create/internal/filter"
+	"github.com/ntsk/gh-issue-bulk-create/internal/template"
+)
+
+// Template is a loaded template: its documents, each creating an issue per
+// row (issue forms are converted into an equivalent Markdown template), the
+// variables it expects as CSV headers and the issue form row data must be
+// prepared for, if any
+type Template struct {
+	File      string
+	Documents []*Document
+	Vars      []string
+	Form      *template.Form
+}
+
+// Document is one document of a template: its Markdown content and the
+// condition, a --where expression, a row must match for the document to
+// create an issue, if any
+type Document struct {
+	Content string
+	When    *filter.Expression
+}
+
+// Loader parses templates. Variables set by the command-line matrix are not
+// expected as CSV headers.
+type Loader struct {
+	matrix   *template.Matrix
+	renderer *template.Renderer
+	parser   *template.Parser
+}
+
+// NewLoader creates a template loader for the --matrix values
+func NewLoader(matrix *template.Matrix, renderer *template.Renderer, parser *template.Parser) *Loader {
+	return &Loader{matrix: matrix, renderer: renderer, parser: parser}
+}
+
+// Parse converts the content of a template file into a Template. Bases and
+// partials of Markdown templates are read from catalog, relative to file.
+func (l *Loader) Parse(catalog template.Catalog, file string, content []byte) (*Template, error) {
+	tmpl := &Template{File: file}
+	if template.IsFormFile(file) {
+		form, err := template.ParseForm(content)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		tmpl.Form = form
+		tmpl.Documents = []*Document{{Content: form.Template()}}
+	} else {
+		composer := template.NewComposer(catalog)
+		for i, text := range template.SplitDocuments(string(content)) {
+			composed, err := composer.Compose(file, text)
+			if err != nil {
+				return nil, err
+			}
+			composed, when, err := template.ExtractCondition(composed)
+			if err != nil {
+				return nil, fmt.Errorf("%s: document %d: %v", file, i+1, err)
+			}
+			document := &Document{Content: composed}
+			if when != "" {
+				expression, err := filter.ParseExpression(when)
+				if err != nil {
+					return nil, fmt.Errorf("%s: document %d: when: %v", file, i+1, err)
+				}
+				document.When = expression
+			}
+			tmpl.Documents = append(tmpl.Documents, document)
+		}
+		if len(tmpl.Documents) == 0 {
+			return nil, fmt.Errorf("%s: template is empty", file)
+		}
+	}
+
+	for _, document := range tmpl.Documents {
+		// Extract variables from the document, including the columns its
+		// condition reads
+		vars := l.renderer.ExtractVariables(document.Content)
+		if document.When != nil {
+			vars = append(vars, document.When.Fields()...)
+		}
+
+		// Variables set by matrix expansion are not expected as CSV headers
+		vars = l.excludeMatrixVariables(vars, document.Content)
+
+		for _, name := range vars {
+			if !slices.Contains(tmpl.Vars, name) {
+				tmpl.Vars = append(tmpl.Vars, name)
+			}
+		}
+	}
+
+	return tmpl, nil
+}
+
+// excludeMatrixVariables removes variables that are provided by the matrix
+// (from the template's front matter or the command line) from templateVars
+func (l *Loader) excludeMatrixVariables(templateVars []string, tmplContent string) []string {
+	// Matrix keys are literal, so rendering without data is enough to read them
+	rendered, err := l.renderer.Render(tmplContent, map[string]string{})
+	if err != nil {
+		return templateVars
+	}
+	matrix, err := l.parser.ParseMatrix(rendered)
+	if err != nil {
+		return templateVars
+	}
+
+	matrixKeys := make(map[string]bool)
+	for _, key := range matrix.Merge(l.matrix).Keys() {
+		matrixKeys[key] = true
+	}
+
+	var filtered []string
+	for _, v := range templateVars {
+		if !matrixKeys[v] {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// TemplateDir loads the templates of a template directory by name, once each
+type TemplateDir struct {
+	loader    *Loader
+	catalog   *template.DirCatalog
+	templates map[string]*Template
+	errs      map[string]error
+}
+
+// NewTemplateDir creates a loader for the templates of dir
+func NewTemplateDir(dir string, loader *Loader) *TemplateDir {
+	return &TemplateDir{
+		loader:    loader,
+		catalog:   template.NewDirCatalog(dir),
+		templates: make(map[string]*Template),
+		errs:      make(map[string]error),
+	}
+}
+
+// Load returns the template called name, with or without its extension
+func (d *TemplateDir) Load(name string) (*Template, error) {
+	key := strings.ToLower(name)
+	if tmpl, ok := d.templates[key]; ok {
+		return tmpl, nil
+	}
+	if err, ok := d.errs[key]; ok {
+		return nil, err
+	}
+
+	tmpl, err := d.read(name)
+	if err != nil {
+		d.errs[key] = err
+		return nil, err
+	}
+	d.templates[key] = tmpl
+	return tmpl, nil
+}
+
+// read reads and parses the template called name
+func (d *TemplateDir) read(name string) (*Template, error) {
+	file, err := template.FindTemplate(d.catalog, name)
+	if err != nil {
+		return nil, err
+	}
+	content, err := d.catalog.ReadTemplate(file)
+	if err != nil {
+		return nil, err
+	}
+	return d.loader.Parse(d.catalog, file, content)
+}
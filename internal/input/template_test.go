@@ -0,0 +1,193 @@
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ntsk/gh-issue-bulk-create/internal/template"
+)
+
+// writeTemplates writes files into a temporary template directory
+func writeTemplates(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestLoaderParse(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"partials/footer.md": "Owner: {{owner}}\n",
+	})
+	cliMatrix := &template.Matrix{Axes: []template.MatrixAxis{{Name: "region", Values: []string{"us", "eu"}}}}
+	loader := NewLoader(cliMatrix, template.NewRenderer(), template.NewParser())
+
+	testCases := []struct {
+		name      string
+		file      string
+		content   string
+		documents int
+		vars      []string
+		err       string
+	}{
+		{
+			name:      "Partials and matrix variables",
+			file:      "deploy.md",
+			content:   "---\ntitle: Deploy {{service}} to {{env}} in {{region}}\nmatrix:\n  env: staging, production\n---\n{{> partials/footer.md}}",
+			documents: 1,
+			vars:      []string{"service", "owner"},
+		},
+		{
+			name:      "Conditions read columns",
+			file:      "split.md",
+			content:   "---\ntitle: Build {{name}}\n---\nBuild\n<!-- issue -->\n---\ntitle: Review {{name}}\nwhen: reviewer\n---\nReview by {{name}}\n",
+			documents: 2,
+			vars:      []string{"name", "reviewer"},
+		},
+		{
+			name:      "Compound conditions read every column",
+			file:      "laptop.md",
+			content:   "---\ntitle: Laptop for {{name}}\nwhen: role == \"engineer\" && !`remote work`\n---\nOrder\n",
+			documents: 1,
+			vars:      []string{"name", "role", "remote work"},
+		},
+		{
+			name:      "Quoted condition with escapes",
+			file:      "urgent.md",
+			content:   "---\ntitle: Escalate {{name}}\nwhen: \"priority == \\\"P1\\\"\"\n---\nEscalate\n",
+			documents: 1,
+			vars:      []string{"name", "priority"},
+		},
+		{
+			name:    "Invalid condition",
+			file:    "invalid.md",
+			content: "---\ntitle: Review\n---\nBuild\n<!-- issue -->\n---\ntitle: Review\nwhen: role ==\n---\nReview\n",
+			err:     "invalid.md: document 2: when: invalid expression 'role =='",
+		},
+		{
+			name:    "Empty template",
+			file:    "empty.md",
+			content: "\n<!-- issue -->\n",
+			err:     "empty.md: template is empty",
+		},
+		{
+			name:    "Missing partial",
+			file:    "broken.md",
+			content: "---\ntitle: Broken\n---\n{{> nowhere.md}}",
+			err:     "failed to read partial nowhere.md",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl, err := loader.Parse(template.NewDirCatalog(dir), tc.file, []byte(tc.content))
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("Expected error containing '%s', got: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if tmpl.File != tc.file {
+				t.Errorf("Expected file %s, got %s", tc.file, tmpl.File)
+			}
+			if len(tmpl.Documents) != tc.documents {
+				t.Errorf("Expected %d documents, got %d", tc.documents, len(tmpl.Documents))
+			}
+			if !reflect.DeepEqual(tmpl.Vars, tc.vars) {
+				t.Errorf("Expected variables %v, got %v", tc.vars, tmpl.Vars)
+			}
+		})
+	}
+}
+
+func TestDocumentCondition(t *testing.T) {
+	loader := NewLoader(nil, template.NewRenderer(), template.NewParser())
+	content := "---\ntitle: Review\nwhen: role == \"engineer\" || role =~ \"(?i)^dev\"\n---\nBody\n"
+	tmpl, err := loader.Parse(template.NewDirCatalog(t.TempDir()), "review.md", []byte(content))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	when := tmpl.Documents[0].When
+	if when == nil {
+		t.Fatal("Expected the document to have a condition")
+	}
+	if strings.Contains(tmpl.Documents[0].Content, "when:") {
+		t.Errorf("Expected when to be removed from the document, got %q", tmpl.Documents[0].Content)
+	}
+
+	testCases := []struct {
+		role     string
+		expected bool
+	}{
+		{role: "engineer", expected: true},
+		{role: "Engineer", expected: false},
+		{role: "DevOps", expected: true},
+		{role: "", expected: false},
+	}
+	for _, tc := range testCases {
+		if matched := when.Match(map[string]string{"role": tc.role}); matched != tc.expected {
+			t.Errorf("Expected role %q to match %v, got %v", tc.role, tc.expected, matched)
+		}
+	}
+}
+
+func TestLoaderParseForm(t *testing.T) {
+	loader := NewLoader(nil, template.NewRenderer(), template.NewParser())
+	form := "name: Bug report\ndescription: File a bug\ntitle: \"[Bug]: \"\nbody:\n  - type: textarea\n    id: what-happened\n    attributes:\n      label: What happened?\n"
+
+	tmpl, err := loader.Parse(template.NewDirCatalog(t.TempDir()), "bug.yml", []byte(form))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if tmpl.Form == nil || len(tmpl.Documents) != 1 {
+		t.Fatalf("Expected an issue form with one document, got %+v", tmpl)
+	}
+	if expected := []string{"title", "what-happened"}; !reflect.DeepEqual(tmpl.Vars, expected) {
+		t.Errorf("Expected variables %v, got %v", expected, tmpl.Vars)
+	}
+}
+
+func TestTemplateDir(t *testing.T) {
+	dir := writeTemplates(t, map[string]string{
+		"bug.md":    "---\ntitle: {{title}}\n---\nBody",
+		"broken.md": "---\ntitle: Broken\n---\n{{> nowhere.md}}",
+	})
+	templates := NewTemplateDir(dir, NewLoader(nil, template.NewRenderer(), template.NewParser()))
+
+	bug, err := templates.Load("bug")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if bug.File != "bug.md" {
+		t.Errorf("Expected bug.md, got %s", bug.File)
+	}
+
+	// Names are matched ignoring case, and loaded once
+	if tmpl, err := templates.Load("BUG"); err != nil || tmpl != bug {
+		t.Errorf("Expected BUG to return the loaded template, got %v, %v", tmpl, err)
+	}
+	if tmpl, err := templates.Load("bug.md"); err != nil || tmpl.File != "bug.md" {
+		t.Errorf("Expected bug.md to load bug.md, got %v, %v", tmpl, err)
+	}
+
+	if _, err := templates.Load("broken"); err == nil {
+		t.Error("Expected an error for a template with a missing partial")
+	}
+	if _, err := templates.Load("missing"); err == nil {
+		t.Error("Expected an error for a missing template")
+	}
+}
@@ -0,0 +1,354 @@
+// Package mapping adapts CSV rows exported by other tools to the columns a
+// template expects. A mapping is read from a YAML file and applied to the rows
+// after they are parsed and before they are rendered.
+package mapping
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// columnRef matches a {{column}} reference in a concat format
+var columnRef = regexp.MustCompile(`{{\s*([^{}]+?)\s*}}`)
+
+// Mapping is a column mapping configuration. Its steps run in the order of
+// the fields: rename, newlines, trim, defaults and then each derived column in
+// turn, so that later steps see the result of earlier ones.
+type Mapping struct {
+	// Rename maps CSV columns to new names
+	Rename map[string]string `yaml:"rename"`
+	// NormalizeNewlines converts CRLF and CR line breaks to LF
+	NormalizeNewlines bool `yaml:"normalize_newlines"`
+	// Trim removes whitespace around every value
+	Trim bool `yaml:"trim"`
+	// Defaults fills empty values, adding the column if the CSV lacks it
+	Defaults map[string]string `yaml:"defaults"`
+	// Derive computes columns from others
+	Derive []Derivation `yaml:"derive"`
+}
+
+// Derivation computes one column with exactly one of its transforms. From is
+// the column a transform reads, defaulting to Column itself.
+type Derivation struct {
+	Column  string    `yaml:"column"`
+	From    string    `yaml:"from"`
+	Concat  string    `yaml:"concat"`
+	Extract string    `yaml:"extract"`
+	Split   *Split    `yaml:"split"`
+	Map     *ValueMap `yaml:"map"`
+	pattern *regexp.Regexp
+}
+
+// Split turns a list separated by Separator (default ",") into one joined
+// by Join (default ", "), the list format of templates, dropping empty items
+type Split struct {
+	Separator string `yaml:"separator"`
+	Join      string `yaml:"join"`
+}
+
+// ValueMap replaces values found in Values, compared ignoring case and
+// surrounding spaces. Other values become Default if set and are kept
+// otherwise.
+type ValueMap struct {
+	Values  map[string]string `yaml:"values"`
+	Default *string           `yaml:"default"`
+	// lookup holds Values by normalized key
+	lookup map[string]string
+}
+
+// Load reads a mapping from a YAML file
+func Load(path string) (*Mapping, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return m, nil
+}
+
+// Parse parses and checks a mapping
+func Parse(content []byte) (*Mapping, error) {
+	var m Mapping
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&m); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("failed to parse mapping: %v", err)
+	}
+
+	// Sorted so that errors name the same columns every time
+	sources := make([]string, 0, len(m.Rename))
+	for from := range m.Rename {
+		sources = append(sources, from)
+	}
+	sort.Strings(sources)
+	renamedFrom := make(map[string]string)
+	for _, from := range sources {
+		to := m.Rename[from]
+		if other, ok := renamedFrom[to]; ok {
+			return nil, fmt.Errorf("columns '%s' and '%s' are both renamed to '%s'", other, from, to)
+		}
+		renamedFrom[to] = from
+	}
+
+	for i := range m.Derive {
+		d := &m.Derive[i]
+		if d.Column == "" {
+			return nil, fmt.Errorf("derive entry %d has no column", i+1)
+		}
+		if d.From == "" {
+			d.From = d.Column
+		}
+
+		transforms := 0
+		for _, set := range []bool{d.Concat != "", d.Extract != "", d.Split != nil, d.Map != nil} {
+			if set {
+				transforms++
+			}
+		}
+		if transforms != 1 {
+			return nil, fmt.Errorf("column '%s' must have exactly one of concat, extract, split or map", d.Column)
+		}
+
+		if d.Extract != "" {
+			pattern, err := regexp.Compile(d.Extract)
+			if err != nil {
+				return nil, fmt.Errorf("column '%s': invalid extract pattern: %v", d.Column, err)
+			}
+			d.pattern = pattern
+		}
+
+		if d.Map != nil {
+			lookup, err := d.Map.index()
+			if err != nil {
+				return nil, fmt.Errorf("column '%s': %v", d.Column, err)
+			}
+			d.Map.lookup = lookup
+		}
+	}
+	return &m, nil
+}
+
+// Apply maps the headers and rows, returning the new headers and rows. Rows
+// are copied, not modified. It fails when a step refers to a column that is
+// not available at that point.
+func (m *Mapping) Apply(headers []string, rows []map[string]string) ([]string, []map[string]string, error) {
+	mapped := make([]map[string]string, len(rows))
+	for i, row := range rows {
+		mapped[i] = make(map[string]string, len(row))
+		for key, value := range row {
+			mapped[i][key] = value
+		}
+	}
+
+	headers, err := m.rename(headers, mapped)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, row := range mapped {
+		for key, value := range row {
+			if m.NormalizeNewlines {
+				value = strings.ReplaceAll(value, "\r\n", "\n")
+				value = strings.ReplaceAll(value, "\r", "\n")
+			}
+			if m.Trim {
+				value = strings.TrimSpace(value)
+			}
+			row[key] = value
+		}
+	}
+
+	// Sorted so that added columns come in a stable order
+	defaults := make([]string, 0, len(m.Defaults))
+	for column := range m.Defaults {
+		defaults = append(defaults, column)
+	}
+	sort.Strings(defaults)
+	for _, column := range defaults {
+		headers = addHeader(headers, column)
+		for _, row := range mapped {
+			if strings.TrimSpace(row[column]) == "" {
+				row[column] = m.Defaults[column]
+			}
+		}
+	}
+
+	for _, d := range m.Derive {
+		for _, column := range d.reads() {
+			if !slices.Contains(headers, column) {
+				return nil, nil, fmt.Errorf("column '%s' read by derived column '%s' not found", column, d.Column)
+			}
+		}
+		headers = addHeader(headers, d.Column)
+		for _, row := range mapped {
+			row[d.Column] = d.apply(row)
+		}
+	}
+
+	return headers, mapped, nil
+}
+
+// rename renames columns in headers and rows. Renaming a column onto another
+// that exists replaces it.
+func (m *Mapping) rename(headers []string, rows []map[string]string) ([]string, error) {
+	sources := make([]string, 0, len(m.Rename))
+	for from := range m.Rename {
+		if !slices.Contains(headers, from) {
+			return nil, fmt.Errorf("column '%s' to rename not found in CSV headers", from)
+		}
+		sources = append(sources, from)
+	}
+	sort.Strings(sources)
+
+	var renamed []string
+	for _, header := range headers {
+		name, ok := m.Rename[header]
+		if !ok {
+			if renamesTo(m.Rename, header) {
+				continue
+			}
+			name = header
+		}
+		renamed = addHeader(renamed, name)
+	}
+
+	for _, row := range rows {
+		values := make(map[string]string, len(sources))
+		for _, from := range sources {
+			values[m.Rename[from]] = row[from]
+			delete(row, from)
+		}
+		for to, value := range values {
+			row[to] = value
+		}
+	}
+	return renamed, nil
+}
+
+// ReadColumns returns the columns the derived columns read, which are used
+// even when no template refers to them
+func (m *Mapping) ReadColumns() []string {
+	var columns []string
+	for _, d := range m.Derive {
+		for _, column := range d.reads() {
+			if !slices.Contains(columns, column) {
+				columns = append(columns, column)
+			}
+		}
+	}
+	return columns
+}
+
+// reads returns the columns the derivation reads
+func (d Derivation) reads() []string {
+	if d.Concat == "" {
+		return []string{d.From}
+	}
+	var columns []string
+	for _, match := range columnRef.FindAllStringSubmatch(d.Concat, -1) {
+		columns = append(columns, match[1])
+	}
+	return columns
+}
+
+// apply computes the derived value for row
+func (d Derivation) apply(row map[string]string) string {
+	value := row[d.From]
+	switch {
+	case d.Concat != "":
+		return columnRef.ReplaceAllStringFunc(d.Concat, func(ref string) string {
+			return row[columnRef.FindStringSubmatch(ref)[1]]
+		})
+	case d.pattern != nil:
+		match := d.pattern.FindStringSubmatch(value)
+		switch {
+		case match == nil:
+			return ""
+		case len(match) > 1:
+			return match[1]
+		default:
+			return match[0]
+		}
+	case d.Split != nil:
+		separator, join := d.Split.Separator, d.Split.Join
+		if separator == "" {
+			separator = ","
+		}
+		if join == "" {
+			join = ", "
+		}
+		var items []string
+		for _, item := range strings.Split(value, separator) {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return strings.Join(items, join)
+	default:
+		if to, ok := d.Map.lookup[normalizeValue(value)]; ok {
+			return to
+		}
+		if d.Map.Default != nil {
+			return *d.Map.Default
+		}
+		return value
+	}
+}
+
+// index maps the normalized keys of Values to their replacements. Keys that
+// only differ in case or surrounding spaces would match the same values, so
+// they are rejected.
+func (v *ValueMap) index() (map[string]string, error) {
+	// Sorted so that errors name the same keys every time
+	keys := make([]string, 0, len(v.Values))
+	for key := range v.Values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lookup := make(map[string]string, len(keys))
+	original := make(map[string]string, len(keys))
+	for _, key := range keys {
+		normalized := normalizeValue(key)
+		if other, ok := original[normalized]; ok {
+			return nil, fmt.Errorf("map values '%s' and '%s' differ only in case or spaces", other, key)
+		}
+		original[normalized] = key
+		lookup[normalized] = v.Values[key]
+	}
+	return lookup, nil
+}
+
+// normalizeValue returns the form values are compared in by a value map
+func normalizeValue(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// addHeader appends column to headers if it is not there yet
+func addHeader(headers []string, column string) []string {
+	if slices.Contains(headers, column) {
+		return headers
+	}
+	return append(headers, column)
+}
+
+// renamesTo reports whether some column is renamed to name
+func renamesTo(rename map[string]string, name string) bool {
+	for _, to := range rename {
+		if to == name {
+			return true
+		}
+	}
+	return false
+}
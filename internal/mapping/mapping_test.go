@@ -0,0 +1,146 @@
+package mapping
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApply(t *testing.T) {
+	config := `
+rename:
+  Summary: title
+  Issue Key: key
+trim: true
+normalize_newlines: true
+defaults:
+  component: general
+derive:
+  - column: title
+    concat: "[{{key}}] {{title}}"
+  - column: ticket
+    from: url
+    extract: "/browse/([A-Z]+-[0-9]+)"
+  - column: labels
+    from: Tags
+    split:
+      separator: ";"
+  - column: priority
+    from: Priority
+    map:
+      values:
+        High: "priority:high"
+        Low: "priority:low"
+  - column: size
+    from: Estimate
+    map:
+      values:
+        "1": small
+      default: large
+`
+	m, err := Parse([]byte(config))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	headers := []string{"Issue Key", "Summary", "Description", "url", "Tags", "Priority", "Estimate", "component"}
+	rows := []map[string]string{
+		{
+			"Issue Key": "WEB-1", "Summary": " Fix login ", "Description": "Line 1\r\nLine 2\rLine 3",
+			"url": "https://jira.example.com/browse/WEB-1", "Tags": "bug; ui;;", "Priority": "high",
+			"Estimate": "1", "component": "",
+		},
+		{
+			"Issue Key": "WEB-2", "Summary": "Add export", "Description": "",
+			"url": "", "Tags": "", "Priority": "Medium", "Estimate": "8", "component": "api",
+		},
+	}
+
+	mappedHeaders, mapped, err := m.Apply(headers, rows)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expectedHeaders := []string{"key", "title", "Description", "url", "Tags", "Priority", "Estimate", "component", "ticket", "labels", "priority", "size"}
+	if !reflect.DeepEqual(mappedHeaders, expectedHeaders) {
+		t.Errorf("Expected headers %v, got %v", expectedHeaders, mappedHeaders)
+	}
+
+	expected := []map[string]string{
+		{
+			"key": "WEB-1", "title": "[WEB-1] Fix login", "Description": "Line 1\nLine 2\nLine 3",
+			"url": "https://jira.example.com/browse/WEB-1", "Tags": "bug; ui;;", "Priority": "high",
+			"Estimate": "1", "component": "general", "ticket": "WEB-1", "labels": "bug, ui",
+			"priority": "priority:high", "size": "small",
+		},
+		{
+			"key": "WEB-2", "title": "[WEB-2] Add export", "Description": "",
+			"url": "", "Tags": "", "Priority": "Medium", "Estimate": "8", "component": "api",
+			"ticket": "", "labels": "", "priority": "Medium", "size": "large",
+		},
+	}
+	if !reflect.DeepEqual(mapped, expected) {
+		t.Errorf("Expected rows:\n%v\nGot:\n%v", expected, mapped)
+	}
+
+	if expected := []string{"key", "title", "url", "Tags", "Priority", "Estimate"}; !reflect.DeepEqual(m.ReadColumns(), expected) {
+		t.Errorf("Expected read columns %v, got %v", expected, m.ReadColumns())
+	}
+
+	// The input rows are left untouched
+	if rows[0]["Summary"] != " Fix login " {
+		t.Errorf("Expected input rows to be unchanged, got %v", rows[0])
+	}
+}
+
+func TestRename(t *testing.T) {
+	m, err := Parse([]byte("rename:\n  a: b\n  b: a\n  Name: title\n"))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	headers, rows, err := m.Apply([]string{"a", "b", "title", "Name"}, []map[string]string{{"a": "1", "b": "2", "title": "old", "Name": "new"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if expected := []string{"b", "a", "title"}; !reflect.DeepEqual(headers, expected) {
+		t.Errorf("Expected headers %v, got %v", expected, headers)
+	}
+	if expected := (map[string]string{"a": "2", "b": "1", "title": "new"}); !reflect.DeepEqual(rows[0], expected) {
+		t.Errorf("Expected row %v, got %v", expected, rows[0])
+	}
+}
+
+func TestMappingErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		config string
+		err    string
+	}{
+		{name: "Unknown field", config: "renames:\n  a: b\n", err: "field renames not found"},
+		{name: "Derived column without name", config: "derive:\n  - concat: x\n", err: "derive entry 1 has no column"},
+		{name: "No transform", config: "derive:\n  - column: x\n", err: "exactly one of concat, extract, split or map"},
+		{name: "Two transforms", config: "derive:\n  - column: x\n    concat: a\n    extract: b\n", err: "exactly one of"},
+		{name: "Invalid pattern", config: "derive:\n  - column: x\n    extract: \"(\"\n", err: "invalid extract pattern"},
+		{name: "Two columns renamed to one", config: "rename:\n  Summary: title\n  Name: title\n", err: "columns 'Name' and 'Summary' are both renamed to 'title'"},
+		{name: "Map values differing in case", config: "derive:\n  - column: priority\n    map:\n      values:\n        High: P1\n        \" high\": P2\n", err: "column 'priority': map values ' high' and 'High' differ only in case or spaces"},
+		{name: "Missing column to rename", config: "rename:\n  Missing: title\n", err: "column 'Missing' to rename not found in CSV headers"},
+		{name: "Missing column to derive from", config: "derive:\n  - column: x\n    concat: \"{{nope}}\"\n", err: "column 'nope' read by derived column 'x' not found"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			m, err := Parse([]byte(tc.config))
+			if err == nil {
+				_, _, err = m.Apply([]string{"title"}, []map[string]string{{"title": "A"}})
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("Expected error containing %q, got: %v", tc.err, err)
+			}
+		})
+	}
+
+	if m, err := Parse(nil); err != nil || m == nil {
+		t.Errorf("Expected an empty mapping to parse, got %v (%v)", m, err)
+	}
+}
@@ -0,0 +1,236 @@
+// Package plan provides plan files: a reviewable record of exactly which
+// issues will be created and the repository state they depend on.
+package plan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+// Version is the plan file format version
+const Version = 1
+
+// Plan is the set of issues to create, as reviewed before applying it
+type Plan struct {
+	Version      int          `json:"version"`
+	CreatedAt    time.Time    `json:"created_at"`
+	Host         string       `json:"host,omitempty"`
+	Repositories []Repository `json:"repositories"`
+	Items        []Item       `json:"items"`
+}
+
+// Repository records the state of a repository that the plan depends on
+type Repository struct {
+	Repo string `json:"repo"`
+	// Labels that exist and must still exist when the plan is applied
+	Labels []string `json:"labels,omitempty"`
+	// Labels that do not exist yet and will be created with the first issue using them
+	NewLabels  []string           `json:"new_labels,omitempty"`
+	Milestones []models.Milestone `json:"milestones,omitempty"`
+}
+
+// Item is a single issue to create
+type Item struct {
+	Source string        `json:"source"`
+	Repo   string        `json:"repo"`
+	Issue  *models.Issue `json:"issue"`
+	Hash   string        `json:"hash"`
+}
+
+// RepositoryState looks up the labels and milestones of a repository
+type RepositoryState interface {
+	ListLabels(repo string) ([]string, error)
+	GetMilestone(repo string, number int) (*models.Milestone, error)
+}
+
+// NewItem creates a plan item with the hash of its content
+func NewItem(source string, repo string, issue *models.Issue) Item {
+	item := Item{Source: source, Repo: repo, Issue: issue}
+	item.Hash = item.ContentHash()
+	return item
+}
+
+// ContentHash returns the SHA-256 hash of the item's repository and issue
+func (i Item) ContentHash() string {
+	data, _ := json.Marshal(struct {
+		Repo  string        `json:"repo"`
+		Issue *models.Issue `json:"issue"`
+	}{i.Repo, i.Issue})
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// New creates a plan for items, recording the labels and milestones they use
+func New(host string, items []Item, state RepositoryState) (*Plan, error) {
+	plan := &Plan{Version: Version, CreatedAt: time.Now().UTC(), Host: host, Items: items}
+
+	for _, repo := range repositories(items) {
+		existing, err := state.ListLabels(repo)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list labels of %s: %v", repo, err)
+		}
+
+		record := Repository{Repo: repo}
+		for _, label := range usedLabels(items, repo) {
+			if containsFold(existing, label) {
+				record.Labels = append(record.Labels, label)
+			} else {
+				record.NewLabels = append(record.NewLabels, label)
+			}
+		}
+
+		for _, number := range usedMilestones(items, repo) {
+			milestone, err := state.GetMilestone(repo, number)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get milestone %d of %s: %v", number, repo, err)
+			}
+			if milestone == nil {
+				return nil, fmt.Errorf("milestone %d not found in %s", number, repo)
+			}
+			record.Milestones = append(record.Milestones, models.Milestone{Number: milestone.Number, Title: milestone.Title})
+		}
+
+		plan.Repositories = append(plan.Repositories, record)
+	}
+
+	return plan, nil
+}
+
+// Write saves a plan as indented JSON
+func Write(path string, plan *Plan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Read loads a plan and checks that it has not been modified since it was written
+func Read(path string) (*Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse plan %s: %v", path, err)
+	}
+	if plan.Version != Version {
+		return nil, fmt.Errorf("unsupported plan version %d (expected %d)", plan.Version, Version)
+	}
+
+	var modified []string
+	for _, item := range plan.Items {
+		if item.Issue == nil || item.ContentHash() != item.Hash {
+			modified = append(modified, item.Source)
+		}
+	}
+	if len(modified) > 0 {
+		return nil, fmt.Errorf("plan %s was modified after it was created: %s", path, strings.Join(modified, ", "))
+	}
+
+	return &plan, nil
+}
+
+// Check compares the recorded repository state with the current one and
+// returns a problem for every label or milestone that was removed or changed
+func (p *Plan) Check(state RepositoryState) ([]string, error) {
+	var problems []string
+	for _, record := range p.Repositories {
+		if len(record.Labels) > 0 {
+			existing, err := state.ListLabels(record.Repo)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list labels of %s: %v", record.Repo, err)
+			}
+			for _, label := range record.Labels {
+				if !containsFold(existing, label) {
+					problems = append(problems, fmt.Sprintf("%s: label '%s' was removed", record.Repo, label))
+				}
+			}
+		}
+
+		for _, recorded := range record.Milestones {
+			milestone, err := state.GetMilestone(record.Repo, recorded.Number)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get milestone %d of %s: %v", recorded.Number, record.Repo, err)
+			}
+			switch {
+			case milestone == nil:
+				problems = append(problems, fmt.Sprintf("%s: milestone %d ('%s') was removed", record.Repo, recorded.Number, recorded.Title))
+			case milestone.Title != recorded.Title:
+				problems = append(problems, fmt.Sprintf("%s: milestone %d was renamed from '%s' to '%s'", record.Repo, recorded.Number, recorded.Title, milestone.Title))
+			}
+		}
+	}
+	return problems, nil
+}
+
+// repositories returns the distinct repositories of items in order of appearance
+func repositories(items []Item) []string {
+	var repos []string
+	seen := make(map[string]bool)
+	for _, item := range items {
+		if !seen[item.Repo] {
+			seen[item.Repo] = true
+			repos = append(repos, item.Repo)
+		}
+	}
+	return repos
+}
+
+// usedLabels returns the distinct labels used by the items of a repository, sorted
+func usedLabels(items []Item, repo string) []string {
+	var labels []string
+	for _, item := range items {
+		if item.Repo != repo {
+			continue
+		}
+		for _, label := range item.Issue.Labels {
+			if !containsFold(labels, label) {
+				labels = append(labels, label)
+			}
+		}
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// usedMilestones returns the distinct milestone numbers used by the items of a
+// repository, sorted. Milestones that are not numbers are left to the API to reject.
+func usedMilestones(items []Item, repo string) []int {
+	var numbers []int
+	seen := make(map[int]bool)
+	for _, item := range items {
+		if item.Repo != repo || item.Issue.Milestone == "" {
+			continue
+		}
+		number, err := strconv.Atoi(item.Issue.Milestone)
+		if err != nil || seen[number] {
+			continue
+		}
+		seen[number] = true
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+	return numbers
+}
+
+// containsFold reports whether values contains value, ignoring case
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,124 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+// MockState provides fixed labels and milestones for testing
+type MockState struct {
+	Labels     map[string][]string
+	Milestones map[string][]models.Milestone
+}
+
+// ListLabels implements the RepositoryState interface for testing
+func (m *MockState) ListLabels(repo string) ([]string, error) {
+	return m.Labels[repo], nil
+}
+
+// GetMilestone implements the RepositoryState interface for testing
+func (m *MockState) GetMilestone(repo string, number int) (*models.Milestone, error) {
+	for i, milestone := range m.Milestones[repo] {
+		if milestone.Number == number {
+			return &m.Milestones[repo][i], nil
+		}
+	}
+	return nil, nil
+}
+
+func testItems() []Item {
+	return []Item{
+		NewItem("row 1", "octo/api", &models.Issue{Title: "First", Labels: []string{"bug", "triage"}, Milestone: "3"}),
+		NewItem("row 2", "octo/api", &models.Issue{Title: "Second", Labels: []string{"Bug"}}),
+		NewItem("row 3", "octo/web", &models.Issue{Title: "Third"}),
+	}
+}
+
+func TestNew(t *testing.T) {
+	state := &MockState{
+		Labels:     map[string][]string{"octo/api": {"bug", "docs"}},
+		Milestones: map[string][]models.Milestone{"octo/api": {{Number: 3, Title: "v1.0", State: "open"}}},
+	}
+
+	plan, err := New("github.com", testItems(), state)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []Repository{
+		{
+			Repo:       "octo/api",
+			Labels:     []string{"bug"},
+			NewLabels:  []string{"triage"},
+			Milestones: []models.Milestone{{Number: 3, Title: "v1.0"}},
+		},
+		{Repo: "octo/web"},
+	}
+	if !reflect.DeepEqual(plan.Repositories, expected) {
+		t.Errorf("Expected repositories %+v, got %+v", expected, plan.Repositories)
+	}
+
+	if _, err := New("github.com", testItems(), &MockState{}); err == nil {
+		t.Error("Expected error for missing milestone, got nil")
+	}
+}
+
+func TestWriteAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	plan := &Plan{Version: Version, Items: testItems()}
+
+	if err := Write(path, plan); err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	read, err := Read(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(read.Items, plan.Items) {
+		t.Errorf("Expected items %+v, got %+v", plan.Items, read.Items)
+	}
+
+	// Editing an issue after the plan was written invalidates its hash
+	data, _ := os.ReadFile(path)
+	edited := strings.Replace(string(data), `"title": "Second"`, `"title": "Edited"`, 1)
+	if err := os.WriteFile(path, []byte(edited), 0o644); err != nil {
+		t.Fatalf("Failed to edit plan: %v", err)
+	}
+	_, err = Read(path)
+	if err == nil || !strings.Contains(err.Error(), "row 2") {
+		t.Errorf("Expected modification error for row 2, got: %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	plan := &Plan{Repositories: []Repository{{
+		Repo:       "octo/api",
+		Labels:     []string{"bug", "docs"},
+		NewLabels:  []string{"triage"},
+		Milestones: []models.Milestone{{Number: 3, Title: "v1.0"}, {Number: 4, Title: "v2.0"}},
+	}}}
+
+	state := &MockState{
+		Labels:     map[string][]string{"octo/api": {"Bug"}},
+		Milestones: map[string][]models.Milestone{"octo/api": {{Number: 3, Title: "v1.0.0"}}},
+	}
+
+	problems, err := plan.Check(state)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := []string{
+		"octo/api: label 'docs' was removed",
+		"octo/api: milestone 3 was renamed from 'v1.0' to 'v1.0.0'",
+		"octo/api: milestone 4 ('v2.0') was removed",
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("Expected problems %v, got %v", expected, problems)
+	}
+}
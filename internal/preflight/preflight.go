@@ -0,0 +1,163 @@
+// Package preflight provides validation of issues against GitHub before any are created.
+// It checks repositories, permissions and assignees once and reports every problem together.
+package preflight
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+// RepositoryChecker looks up what preflight needs to know about a repository
+type RepositoryChecker interface {
+	GetRepository(repo string) (*models.Repository, error)
+	CheckAssignee(repo string, user string) (bool, error)
+}
+
+// Item is an issue to be checked along with a description of where it came from
+type Item struct {
+	Source string
+	Repo   string
+	Issue  *models.Issue
+}
+
+// Problem is a single preflight failure
+type Problem struct {
+	Source  string
+	Message string
+}
+
+// String formats the problem for display
+func (p Problem) String() string {
+	if p.Source == "" {
+		return p.Message
+	}
+	return fmt.Sprintf("%s: %s", p.Source, p.Message)
+}
+
+// Checker runs preflight checks, looking up each repository and assignee only once
+type Checker struct {
+	client    RepositoryChecker
+	repos     map[string]*repoResult
+	assignees map[string]bool
+}
+
+// repoResult caches a repository lookup, including failures
+type repoResult struct {
+	repo *models.Repository
+	err  error
+}
+
+// NewChecker creates a new preflight checker
+func NewChecker(client RepositoryChecker) *Checker {
+	return &Checker{
+		client:    client,
+		repos:     make(map[string]*repoResult),
+		assignees: make(map[string]bool),
+	}
+}
+
+// Check validates every item and returns all problems found. Problems that
+// concern a whole repository are reported once rather than for every row.
+func (c *Checker) Check(items []Item) ([]Problem, error) {
+	var problems []Problem
+	reported := make(map[string]bool)
+	report := func(key string, problem Problem) {
+		if !reported[key] {
+			reported[key] = true
+			problems = append(problems, problem)
+		}
+	}
+
+	for _, item := range items {
+		result := c.repository(item.Repo)
+		if result.err != nil {
+			report("repo|"+item.Repo, Problem{Message: fmt.Sprintf("repository %s: %v", item.Repo, result.err)})
+			continue
+		}
+		repo := result.repo
+
+		if repo.Archived {
+			report("archived|"+item.Repo, Problem{Message: fmt.Sprintf("repository %s is archived", item.Repo)})
+			continue
+		}
+		if !repo.HasIssues {
+			report("issues|"+item.Repo, Problem{Message: fmt.Sprintf("repository %s has issues disabled", item.Repo)})
+			continue
+		}
+
+		// GitHub silently drops labels, assignees and milestones set by users without push access
+		if needs := pushOnlyFields(item.Issue); len(needs) > 0 && !repo.Permissions.CanPush() {
+			report("push|"+item.Repo, Problem{Message: fmt.Sprintf(
+				"%s permission on %s is not enough to set %s (push access required)",
+				repo.Permissions.Level(), item.Repo, strings.Join(needs, ", "))})
+		}
+		if item.Issue.State == "closed" && !repo.Permissions.CanTriage() {
+			report("triage|"+item.Repo, Problem{Message: fmt.Sprintf(
+				"%s permission on %s is not enough to close issues (triage access required)",
+				repo.Permissions.Level(), item.Repo)})
+		}
+
+		for _, user := range item.Issue.Assignees {
+			ok, err := c.assignable(item.Repo, user)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check assignee %s in %s: %v", user, item.Repo, err)
+			}
+			if !ok {
+				problems = append(problems, Problem{
+					Source:  item.Source,
+					Message: fmt.Sprintf("%s cannot be assigned to issues in %s", user, item.Repo),
+				})
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+// pushOnlyFields lists the fields set on the issue that require push access
+func pushOnlyFields(issue *models.Issue) []string {
+	var fields []string
+	if len(issue.Labels) > 0 {
+		fields = append(fields, "labels")
+	}
+	if len(issue.Assignees) > 0 {
+		fields = append(fields, "assignees")
+	}
+	if issue.Milestone != "" {
+		fields = append(fields, "milestone")
+	}
+	if issue.Locked {
+		fields = append(fields, "lock")
+	}
+	if issue.Pinned {
+		fields = append(fields, "pin")
+	}
+	return fields
+}
+
+// repository looks up a repository once
+func (c *Checker) repository(repo string) *repoResult {
+	if result, ok := c.repos[repo]; ok {
+		return result
+	}
+	repository, err := c.client.GetRepository(repo)
+	result := &repoResult{repo: repository, err: err}
+	c.repos[repo] = result
+	return result
+}
+
+// assignable checks an assignee once per repository
+func (c *Checker) assignable(repo, user string) (bool, error) {
+	key := repo + "|" + strings.ToLower(user)
+	if ok, found := c.assignees[key]; found {
+		return ok, nil
+	}
+	ok, err := c.client.CheckAssignee(repo, user)
+	if err != nil {
+		return false, err
+	}
+	c.assignees[key] = ok
+	return ok, nil
+}
@@ -0,0 +1,110 @@
+package preflight
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+// MockRepositoryChecker provides fixed repositories and assignees for testing
+type MockRepositoryChecker struct {
+	Repositories  map[string]*models.Repository
+	Assignable    map[string]bool
+	RepoCalls     int
+	AssigneeCalls int
+}
+
+// GetRepository implements the RepositoryChecker interface for testing
+func (m *MockRepositoryChecker) GetRepository(repo string) (*models.Repository, error) {
+	m.RepoCalls++
+	repository, ok := m.Repositories[repo]
+	if !ok {
+		return nil, errors.New("not found or not accessible")
+	}
+	return repository, nil
+}
+
+// CheckAssignee implements the RepositoryChecker interface for testing
+func (m *MockRepositoryChecker) CheckAssignee(repo string, user string) (bool, error) {
+	m.AssigneeCalls++
+	return m.Assignable[repo+"/"+user], nil
+}
+
+func TestCheck(t *testing.T) {
+	client := &MockRepositoryChecker{
+		Repositories: map[string]*models.Repository{
+			"octo/api":      {HasIssues: true, Permissions: models.RepositoryPermissions{Push: true, Triage: true, Pull: true}},
+			"octo/readonly": {HasIssues: true, Permissions: models.RepositoryPermissions{Pull: true}},
+			"octo/noissues": {HasIssues: false, Permissions: models.RepositoryPermissions{Admin: true}},
+			"octo/archived": {HasIssues: true, Archived: true, Permissions: models.RepositoryPermissions{Admin: true}},
+		},
+		Assignable: map[string]bool{"octo/api/alice": true},
+	}
+
+	items := []Item{
+		{Source: "row 1", Repo: "octo/api", Issue: &models.Issue{Labels: []string{"bug"}, Assignees: []string{"alice", "mallory"}}},
+		{Source: "row 2", Repo: "octo/api", Issue: &models.Issue{Assignees: []string{"mallory"}}},
+		{Source: "row 3", Repo: "octo/readonly", Issue: &models.Issue{Labels: []string{"bug"}, State: "closed"}},
+		{Source: "row 4", Repo: "octo/readonly", Issue: &models.Issue{Labels: []string{"bug"}}},
+		{Source: "row 5", Repo: "octo/readonly", Issue: &models.Issue{}},
+		{Source: "row 6", Repo: "octo/noissues", Issue: &models.Issue{}},
+		{Source: "row 7", Repo: "octo/archived", Issue: &models.Issue{}},
+		{Source: "row 8", Repo: "octo/missing", Issue: &models.Issue{}},
+		{Source: "row 9", Repo: "octo/missing", Issue: &models.Issue{}},
+	}
+
+	checker := NewChecker(client)
+	problems, err := checker.Check(items)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	var messages []string
+	for _, problem := range problems {
+		messages = append(messages, problem.String())
+	}
+
+	expected := []string{
+		"row 1: mallory cannot be assigned to issues in octo/api",
+		"row 2: mallory cannot be assigned to issues in octo/api",
+		"read permission on octo/readonly is not enough to set labels (push access required)",
+		"read permission on octo/readonly is not enough to close issues (triage access required)",
+		"repository octo/noissues has issues disabled",
+		"repository octo/archived is archived",
+		"repository octo/missing: not found or not accessible",
+	}
+	if !reflect.DeepEqual(messages, expected) {
+		t.Errorf("Expected problems:\n%v\ngot:\n%v", expected, messages)
+	}
+
+	// Each repository and assignee is looked up once
+	if client.RepoCalls != 5 {
+		t.Errorf("Expected 5 repository lookups, got %d", client.RepoCalls)
+	}
+	if client.AssigneeCalls != 2 {
+		t.Errorf("Expected 2 assignee lookups, got %d", client.AssigneeCalls)
+	}
+}
+
+func TestCheckWithoutProblems(t *testing.T) {
+	client := &MockRepositoryChecker{
+		Repositories: map[string]*models.Repository{
+			"octo/api": {HasIssues: true, Permissions: models.RepositoryPermissions{Maintain: true}},
+		},
+		Assignable: map[string]bool{"octo/api/alice": true},
+	}
+
+	items := []Item{
+		{Source: "row 1", Repo: "octo/api", Issue: &models.Issue{Labels: []string{"bug"}, Assignees: []string{"alice"}, Milestone: "v1"}},
+	}
+
+	problems, err := NewChecker(client).Check(items)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if len(problems) != 0 {
+		t.Errorf("Expected no problems, got %v", problems)
+	}
+}
@@ -0,0 +1,108 @@
+// Package runlog records the issues each run created so that a run can be
+// reviewed or undone later.
+package runlog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultDir is the directory runs are recorded in, relative to the working directory
+const DefaultDir = ".gh-issue-bulk-create/runs"
+
+// Run is the record of a single run
+type Run struct {
+	ID        string     `json:"id"`
+	StartedAt time.Time  `json:"started_at"`
+	Host      string     `json:"host,omitempty"`
+	Issues    []Issue    `json:"issues"`
+	UndoneAt  *time.Time `json:"undone_at,omitempty"`
+	// saved is set once the run has a file of its own
+	saved bool
+}
+
+// Issue is an issue created by a run
+type Issue struct {
+	Source string `json:"source"`
+	Repo   string `json:"repo"`
+	Number int    `json:"number"`
+	NodeID string `json:"node_id"`
+	URL    string `json:"url"`
+	Title  string `json:"title"`
+	// State is "closed" for issues created closed
+	State string `json:"state,omitempty"`
+}
+
+// NewRun creates a run record identified by its start time
+func NewRun(host string, startedAt time.Time) *Run {
+	return &Run{
+		ID:        startedAt.UTC().Format("20060102-150405"),
+		StartedAt: startedAt.UTC(),
+		Host:      host,
+	}
+}
+
+// Add records an issue created by the run
+func (r *Run) Add(issue Issue) {
+	r.Issues = append(r.Issues, issue)
+}
+
+// Save writes the run to DIR/ID.json and returns the file path. The first save
+// of a new run creates the file without replacing another one: if a run
+// started in the same second already has the ID, a suffix such as "-2" is
+// added to the ID. Later saves and saves of loaded runs rewrite the file.
+func Save(dir string, run *Run) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+
+	if !run.saved {
+		base := run.ID
+		for n := 2; ; n++ {
+			file, err := os.OpenFile(filepath.Join(dir, run.ID+".json"), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+			if err == nil {
+				file.Close()
+				break
+			}
+			if !os.IsExist(err) {
+				return "", err
+			}
+			run.ID = fmt.Sprintf("%s-%d", base, n)
+		}
+		run.saved = true
+	}
+
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, run.ID+".json")
+	return path, os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// Load reads a run by ID from dir, or from a file path
+func Load(dir string, ref string) (*Run, error) {
+	path := ref
+	if !strings.HasSuffix(ref, ".json") {
+		path = filepath.Join(dir, ref+".json")
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("run '%s' not found in %s", ref, dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse run %s: %v", path, err)
+	}
+	run.saved = true
+	return &run, nil
+}
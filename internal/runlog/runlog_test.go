@@ -0,0 +1,82 @@
+package runlog
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "runs")
+	run := NewRun("github.com", time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC))
+	run.Add(Issue{Source: "row 1", Repo: "octo/api", Number: 12, NodeID: "I_12", URL: "https://github.com/octo/api/issues/12", Title: "First"})
+	run.Add(Issue{Source: "row 2", Repo: "octo/api", Number: 13, NodeID: "I_13", URL: "https://github.com/octo/api/issues/13", Title: "Second", State: "closed"})
+
+	if run.ID != "20261018-093000" {
+		t.Errorf("Expected run ID '20261018-093000', got '%s'", run.ID)
+	}
+
+	path, err := Save(dir, run)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if path != filepath.Join(dir, "20261018-093000.json") {
+		t.Errorf("Unexpected run path '%s'", path)
+	}
+
+	for _, ref := range []string{run.ID, path} {
+		loaded, err := Load(dir, ref)
+		if err != nil {
+			t.Fatalf("Expected no error loading '%s', got: %v", ref, err)
+		}
+		if !reflect.DeepEqual(*loaded, *run) {
+			t.Errorf("Expected run %+v, got %+v", run, loaded)
+		}
+	}
+
+	if _, err := Load(dir, "20200101-000000"); err == nil {
+		t.Error("Expected error for unknown run, got nil")
+	}
+}
+
+func TestSaveKeepsRunsOfTheSameSecond(t *testing.T) {
+	dir := t.TempDir()
+	startedAt := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+
+	first := NewRun("github.com", startedAt)
+	second := NewRun("github.com", startedAt)
+	third := NewRun("github.com", startedAt)
+	for _, run := range []*Run{first, second, third} {
+		if _, err := Save(dir, run); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+	if first.ID != "20261018-093000" || second.ID != "20261018-093000-2" || third.ID != "20261018-093000-3" {
+		t.Errorf("Expected distinct IDs, got '%s', '%s' and '%s'", first.ID, second.ID, third.ID)
+	}
+
+	// Saving again rewrites the run's own file
+	first.Add(Issue{Repo: "octo/api", Number: 1})
+	second.Add(Issue{Repo: "octo/api", Number: 2})
+	for _, run := range []*Run{first, second} {
+		if _, err := Save(dir, run); err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+	}
+	for _, run := range []*Run{first, second, third} {
+		loaded, err := Load(dir, run.ID)
+		if err != nil {
+			t.Fatalf("Expected no error, got: %v", err)
+		}
+		if !reflect.DeepEqual(loaded.Issues, run.Issues) {
+			t.Errorf("Expected run %s to have issues %v, got %v", run.ID, run.Issues, loaded.Issues)
+		}
+	}
+
+	// A loaded run is rewritten in place
+	loaded, _ := Load(dir, first.ID)
+	if _, err := Save(dir, loaded); err != nil || loaded.ID != first.ID {
+		t.Errorf("Expected the loaded run to keep ID '%s', got '%s' (%v)", first.ID, loaded.ID, err)
+	}
+}
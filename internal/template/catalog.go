@@ -0,0 +1,144 @@
+package template
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TemplateDir is where GitHub looks for a repository's issue templates
+const TemplateDir = ".github/ISSUE_TEMPLATE"
+
+// templateExtensions lists the extensions of issue templates, in the order a
+// name without an extension is resolved
+var templateExtensions = []string{".md", ".yml", ".yaml"}
+
+// Catalog lists and reads the issue templates of a repository
+type Catalog interface {
+	// ListTemplates returns the file names of the templates, sorted
+	ListTemplates() ([]string, error)
+	// ReadTemplate returns the content of a template by file name
+	ReadTemplate(file string) ([]byte, error)
+}
+
+// DirCatalog reads issue templates from a local directory
+type DirCatalog struct {
+	Dir string
+}
+
+// NewDirCatalog creates a catalog of the templates in dir
+func NewDirCatalog(dir string) *DirCatalog {
+	return &DirCatalog{Dir: dir}
+}
+
+// ListTemplates implements Catalog
+func (c *DirCatalog) ListTemplates() ([]string, error) {
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() {
+			names = append(names, entry.Name())
+		}
+	}
+	return TemplateFiles(names), nil
+}
+
+// ReadTemplate implements Catalog
+func (c *DirCatalog) ReadTemplate(file string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(c.Dir, file))
+}
+
+// TemplateFiles returns the names of issue templates among file names,
+// sorted. The template chooser's config.yml is not a template.
+func TemplateFiles(names []string) []string {
+	var files []string
+	for _, name := range names {
+		base := strings.ToLower(name)
+		if base == "config.yml" || base == "config.yaml" || !hasTemplateExtension(base) {
+			continue
+		}
+		files = append(files, name)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// TemplateName returns the name of a template file without its extension
+func TemplateName(file string) string {
+	return strings.TrimSuffix(file, filepath.Ext(file))
+}
+
+// FindTemplate returns the file name of the template called name in catalog.
+// name may include the extension; without one, .md is preferred over .yml
+// and .yaml.
+func FindTemplate(catalog Catalog, name string) (string, error) {
+	files, err := catalog.ListTemplates()
+	if err != nil {
+		return "", err
+	}
+
+	candidates := []string{name}
+	if !hasTemplateExtension(strings.ToLower(name)) {
+		candidates = nil
+		for _, ext := range templateExtensions {
+			candidates = append(candidates, name+ext)
+		}
+	}
+	for _, candidate := range candidates {
+		for _, file := range files {
+			if strings.EqualFold(file, candidate) {
+				return file, nil
+			}
+		}
+	}
+
+	var available []string
+	for _, file := range files {
+		available = append(available, TemplateName(file))
+	}
+	if len(available) == 0 {
+		return "", fmt.Errorf("template '%s' not found: there are no issue templates", name)
+	}
+	return "", fmt.Errorf("template '%s' not found (available: %s)", name, strings.Join(available, ", "))
+}
+
+// DescribeTemplate returns the name and description GitHub shows in the
+// template chooser: name and about from Markdown front matter, or name and
+// description of an issue form. Both are empty if the template has none.
+func DescribeTemplate(file string, content []byte) (string, string) {
+	var meta struct {
+		Name        string `yaml:"name"`
+		About       string `yaml:"about"`
+		Description string `yaml:"description"`
+	}
+
+	if IsFormFile(file) {
+		if yaml.Unmarshal(content, &meta) != nil {
+			return "", ""
+		}
+		return meta.Name, meta.Description
+	}
+
+	parts := strings.SplitN(string(content), "---", 3)
+	if !strings.HasPrefix(string(content), "---") || len(parts) < 3 || yaml.Unmarshal([]byte(parts[1]), &meta) != nil {
+		return "", ""
+	}
+	return meta.Name, meta.About
+}
+
+// hasTemplateExtension reports whether a lowercase file name has an issue template extension
+func hasTemplateExtension(name string) bool {
+	for _, ext := range templateExtensions {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
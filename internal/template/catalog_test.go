@@ -0,0 +1,73 @@
+package template
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDirCatalog(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"bug_report.yml":  "name: Bug report\ndescription: File a bug\nbody:\n  - type: input\n    id: a\n",
+		"feature.md":      "---\nname: Feature request\nabout: Suggest an idea\n---\nBody",
+		"feature.yml":     "name: Feature form\nbody: []\n",
+		"config.yml":      "blank_issues_enabled: false\n",
+		"README.txt":      "not a template",
+		"chore.markdown~": "backup",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	catalog := NewDirCatalog(dir)
+
+	listed, err := catalog.ListTemplates()
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if expected := []string{"bug_report.yml", "feature.md", "feature.yml"}; !reflect.DeepEqual(listed, expected) {
+		t.Errorf("Expected templates %v, got %v", expected, listed)
+	}
+
+	testCases := []struct {
+		name     string
+		expected string
+	}{
+		{name: "bug_report", expected: "bug_report.yml"},
+		{name: "Bug_Report", expected: "bug_report.yml"},
+		{name: "feature", expected: "feature.md"},
+		{name: "feature.yml", expected: "feature.yml"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			file, err := FindTemplate(catalog, tc.name)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if file != tc.expected {
+				t.Errorf("Expected %s, got %s", tc.expected, file)
+			}
+		})
+	}
+
+	_, err = FindTemplate(catalog, "chore")
+	if err == nil || !strings.Contains(err.Error(), "available: bug_report, feature, feature") {
+		t.Errorf("Expected not found error listing templates, got: %v", err)
+	}
+
+	content, err := catalog.ReadTemplate("feature.md")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if name, about := DescribeTemplate("feature.md", content); name != "Feature request" || about != "Suggest an idea" {
+		t.Errorf("Expected Markdown template description, got %q, %q", name, about)
+	}
+	content, _ = catalog.ReadTemplate("bug_report.yml")
+	if name, about := DescribeTemplate("bug_report.yml", content); name != "Bug report" || about != "File a bug" {
+		t.Errorf("Expected issue form description, got %q, %q", name, about)
+	}
+}
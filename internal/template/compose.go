@@ -0,0 +1,336 @@
+package template
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// partialTag matches a {{> name}} include
+var partialTag = regexp.MustCompile(`{{\s*>\s*([^}\s]+)\s*}}`)
+
+// blockTag matches the opening tag of a {{$name}}...{{/name}} block
+var blockTag = regexp.MustCompile(`{{\s*\$\s*([^}\s]+)\s*}}`)
+
+// Composer resolves template inheritance and partials. A template whose front
+// matter has "extends: base.md" takes the front matter and body of base.md:
+// its own front matter fields replace those of the base, and its body may only
+// override the base's named blocks, written {{$name}}default{{/name}}.
+// {{> name}} includes another file. Paths are relative to the template that
+// refers to them and read through the catalog.
+type Composer struct {
+	catalog Catalog
+}
+
+// NewComposer creates a composer reading templates and partials from catalog
+func NewComposer(catalog Catalog) *Composer {
+	return &Composer{catalog: catalog}
+}
+
+// Compose returns the template file with content flattened into a single
+// template: partials included, bases applied and blocks replaced by their
+// content
+func (c *Composer) Compose(file string, content string) (string, error) {
+	composed, err := c.compose(file, content, nil)
+	if err != nil {
+		return "", err
+	}
+	stripped, err := stripBlocks(composed)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", file, err)
+	}
+	return stripped, nil
+}
+
+// compose resolves the partials and base of a template, keeping its blocks
+// so that templates extending it can override them. stack holds the
+// templates being composed, to detect cycles.
+func (c *Composer) compose(file string, content string, stack []string) (string, error) {
+	stack, err := push(stack, file, "extends")
+	if err != nil {
+		return "", err
+	}
+
+	content, err = c.includePartials(file, content, stack)
+	if err != nil {
+		return "", err
+	}
+
+	frontMatter, body, ok := splitTemplate(content)
+	if !ok {
+		return content, nil
+	}
+	fields := splitFrontMatterFields(frontMatter)
+	base := ""
+	for i, field := range fields {
+		if field.key == "extends" {
+			base = strings.Trim(strings.TrimSpace(strings.TrimPrefix(field.text, "extends:")), `"'`)
+			fields = append(fields[:i], fields[i+1:]...)
+			break
+		}
+	}
+	if base == "" {
+		return content, nil
+	}
+
+	basePath := path.Join(path.Dir(file), base)
+	baseContent, err := c.catalog.ReadTemplate(basePath)
+	if err != nil {
+		return "", fmt.Errorf("%s: failed to read base template %s: %v", file, base, err)
+	}
+	composedBase, err := c.compose(basePath, string(baseContent), stack)
+	if err != nil {
+		return "", err
+	}
+	baseFrontMatter, baseBody, ok := splitTemplate(composedBase)
+	if !ok {
+		baseBody = composedBase
+	}
+
+	overrides, err := parseBlocks(file, body)
+	if err != nil {
+		return "", err
+	}
+	merged, err := overrideBlocks(baseBody, overrides)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v in %s", file, err, base)
+	}
+
+	return "---\n" + mergeFrontMatter(splitFrontMatterFields(baseFrontMatter), fields) + "---\n" + merged, nil
+}
+
+// includePartials replaces every {{> name}} in content with the partial,
+// itself with its partials included
+func (c *Composer) includePartials(file string, content string, stack []string) (string, error) {
+	var firstErr error
+	included := partialTag.ReplaceAllStringFunc(content, func(tag string) string {
+		if firstErr != nil {
+			return ""
+		}
+		name := partialTag.FindStringSubmatch(tag)[1]
+		partialPath := path.Join(path.Dir(file), name)
+
+		partialStack, err := push(stack, partialPath, "include")
+		if err != nil {
+			firstErr = err
+			return ""
+		}
+		partial, err := c.catalog.ReadTemplate(partialPath)
+		if err != nil {
+			firstErr = fmt.Errorf("%s: failed to read partial %s: %v", file, name, err)
+			return ""
+		}
+		text, err := c.includePartials(partialPath, strings.TrimSuffix(string(partial), "\n"), partialStack)
+		if err != nil {
+			firstErr = err
+			return ""
+		}
+		return text
+	})
+	return included, firstErr
+}
+
+// push adds file to the stack of templates being composed, reporting a cycle
+// if it is already on it
+func push(stack []string, file string, relation string) ([]string, error) {
+	for i, f := range stack {
+		if f == file {
+			chain := append(append([]string{}, stack[i:]...), file)
+			return nil, fmt.Errorf("%s cycle: %s", relation, strings.Join(chain, " -> "))
+		}
+	}
+	return append(append([]string{}, stack...), file), nil
+}
+
+// templateBlock is a {{$name}}...{{/name}} block found in a template
+type templateBlock struct {
+	name       string
+	start, end int // the whole block, tags included
+	content    string
+}
+
+// findBlocks returns the top-level blocks of text in order
+func findBlocks(text string) ([]templateBlock, error) {
+	var blocks []templateBlock
+	offset := 0
+	for {
+		loc := blockTag.FindStringSubmatchIndex(text[offset:])
+		if loc == nil {
+			return blocks, nil
+		}
+		name := text[offset+loc[2] : offset+loc[3]]
+		contentStart := offset + loc[1]
+
+		closing := regexp.MustCompile(`{{\s*/\s*` + regexp.QuoteMeta(name) + `\s*}}`)
+		end := closing.FindStringIndex(text[contentStart:])
+		if end == nil {
+			return nil, fmt.Errorf("block '%s' is not closed with {{/%s}}", name, name)
+		}
+
+		blocks = append(blocks, templateBlock{
+			name:    name,
+			start:   offset + loc[0],
+			end:     contentStart + end[1],
+			content: text[contentStart : contentStart+end[0]],
+		})
+		offset = contentStart + end[1]
+	}
+}
+
+// parseBlocks returns the blocks a template extending another overrides. Text
+// outside of blocks is an error since there is nowhere to put it.
+func parseBlocks(file string, body string) (map[string]string, error) {
+	blocks, err := findBlocks(body)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", file, err)
+	}
+
+	overrides := make(map[string]string)
+	last := 0
+	for _, block := range blocks {
+		if strings.TrimSpace(body[last:block.start]) != "" {
+			return nil, fmt.Errorf("%s: text outside of blocks; a template that extends another can only override its blocks", file)
+		}
+		overrides[block.name] = block.content
+		last = block.end
+	}
+	if strings.TrimSpace(body[last:]) != "" {
+		return nil, fmt.Errorf("%s: text outside of blocks; a template that extends another can only override its blocks", file)
+	}
+	return overrides, nil
+}
+
+// overrideBlocks replaces the content of the blocks of body that are in
+// overrides, keeping their tags. Every override must match a block.
+func overrideBlocks(body string, overrides map[string]string) (string, error) {
+	used := make(map[string]bool)
+	var replace func(text string) (string, error)
+	replace = func(text string) (string, error) {
+		blocks, err := findBlocks(text)
+		if err != nil {
+			return "", err
+		}
+		var b strings.Builder
+		last := 0
+		for _, block := range blocks {
+			content, ok := overrides[block.name]
+			if ok {
+				used[block.name] = true
+			} else if content, err = replace(block.content); err != nil {
+				return "", err
+			}
+			b.WriteString(text[last:block.start])
+			fmt.Fprintf(&b, "{{$%s}}%s{{/%s}}", block.name, content, block.name)
+			last = block.end
+		}
+		b.WriteString(text[last:])
+		return b.String(), nil
+	}
+
+	result, err := replace(body)
+	if err != nil {
+		return "", err
+	}
+	for name := range overrides {
+		if !used[name] {
+			return "", fmt.Errorf("block '%s' is not defined", name)
+		}
+	}
+	return result, nil
+}
+
+// stripBlocks replaces every block with its content
+func stripBlocks(text string) (string, error) {
+	blocks, err := findBlocks(text)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	last := 0
+	for _, block := range blocks {
+		content, err := stripBlocks(block.content)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString(text[last:block.start])
+		b.WriteString(content)
+		last = block.end
+	}
+	b.WriteString(text[last:])
+	return b.String(), nil
+}
+
+// frontMatterField is a top-level front matter field with its raw text,
+// including indented continuation lines
+type frontMatterField struct {
+	key  string
+	text string
+}
+
+// splitFrontMatterFields splits raw front matter into its top-level fields
+// without parsing it as YAML, since it may contain unrendered variables.
+// Lines before the first field are kept with an empty key.
+func splitFrontMatterFields(frontMatter string) []frontMatterField {
+	var fields []frontMatterField
+	for _, line := range strings.SplitAfter(frontMatter, "\n") {
+		if line == "" {
+			continue
+		}
+		// Indented and list lines continue the current field
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, "-") {
+			if key, _, ok := strings.Cut(line, ":"); ok {
+				fields = append(fields, frontMatterField{key: strings.TrimSpace(key), text: line})
+				continue
+			}
+		}
+		if len(fields) == 0 {
+			fields = append(fields, frontMatterField{})
+		}
+		fields[len(fields)-1].text += line
+	}
+	return fields
+}
+
+// mergeFrontMatter returns the base fields with those of the same key replaced
+// by the child's, followed by the child's other fields
+func mergeFrontMatter(base []frontMatterField, child []frontMatterField) string {
+	var b strings.Builder
+	written := make(map[string]bool)
+	for _, field := range base {
+		text := field.text
+		for _, override := range child {
+			if override.key != "" && override.key == field.key {
+				text = override.text
+				written[field.key] = true
+			}
+		}
+		b.WriteString(ensureNewline(text))
+	}
+	for _, field := range child {
+		if field.key != "" && !written[field.key] {
+			b.WriteString(ensureNewline(field.text))
+		}
+	}
+	return b.String()
+}
+
+// splitTemplate splits a template into its raw front matter and body
+func splitTemplate(content string) (string, string, bool) {
+	if !strings.HasPrefix(content, "---") {
+		return "", content, false
+	}
+	parts := strings.SplitN(content, "---", 3)
+	if len(parts) < 3 {
+		return "", content, false
+	}
+	return strings.TrimPrefix(parts[1], "\n"), strings.TrimPrefix(parts[2], "\n"), true
+}
+
+// ensureNewline adds a trailing newline to text if it has none
+func ensureNewline(text string) string {
+	if strings.HasSuffix(text, "\n") {
+		return text
+	}
+	return text + "\n"
+}
@@ -0,0 +1,113 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// mapCatalog is a catalog of templates held in memory
+type mapCatalog map[string]string
+
+func (c mapCatalog) ListTemplates() ([]string, error) {
+	var names []string
+	for name := range c {
+		names = append(names, name)
+	}
+	return TemplateFiles(names), nil
+}
+
+func (c mapCatalog) ReadTemplate(file string) ([]byte, error) {
+	content, ok := c[file]
+	if !ok {
+		return nil, fmt.Errorf("%s not found", file)
+	}
+	return []byte(content), nil
+}
+
+func TestComposer(t *testing.T) {
+	catalog := mapCatalog{
+		"base.md": "---\ntitle: {{title}}\nlabels:\n  - triage\n---\n" +
+			"## Summary\n\n{{$summary}}{{description}}{{/summary}}\n\n" +
+			"{{$details}}## Details\n\n{{$steps}}No steps{{/steps}}{{/details}}\n\n{{> partials/footer.md}}\n",
+		"partials/footer.md":    "---\n{{> signature.md}}",
+		"partials/signature.md": "Filed by {{owner}}\n",
+		"bug.md": "---\nextends: base.md\nlabels:\n  - bug\nassignees: {{owner}}\n---\n" +
+			"{{$steps}}1. {{steps}}{{/steps}}\n",
+		"urgent/bug.md": "---\nextends: \"../bug.md\"\ntitle: \"[URGENT] {{title}}\"\n---\n" +
+			"{{$summary}}**Urgent**: {{description}}{{/summary}}\n",
+		"include.md":        "---\ntitle: {{title}}\n---\n{{> partials/footer.md}}",
+		"outside.md":        "---\nextends: base.md\n---\nStray text\n{{$summary}}x{{/summary}}",
+		"unknown.md":        "---\nextends: base.md\n---\n{{$nothing}}x{{/nothing}}",
+		"unclosed.md":       "---\ntitle: t\n---\n{{$summary}}x",
+		"missing.md":        "---\ntitle: t\n---\n{{> nowhere.md}}",
+		"loop/a.md":         "A {{> b.md}}",
+		"loop/b.md":         "B {{> a.md}}",
+		"loop/self.md":      "---\nextends: self.md\n---\n",
+		"loop/parent.md":    "---\nextends: child.md\n---\n",
+		"loop/child.md":     "---\nextends: parent.md\n---\n",
+		"no_frontmatter.md": "Just {{> partials/signature.md}}",
+	}
+	composer := NewComposer(catalog)
+
+	testCases := []struct {
+		name     string
+		file     string
+		expected string
+		err      string
+	}{
+		{
+			name: "Template without a base",
+			file: "base.md",
+			expected: "---\ntitle: {{title}}\nlabels:\n  - triage\n---\n" +
+				"## Summary\n\n{{description}}\n\n## Details\n\nNo steps\n\n---\nFiled by {{owner}}\n",
+		},
+		{
+			name: "Template overriding a nested block",
+			file: "bug.md",
+			expected: "---\ntitle: {{title}}\nlabels:\n  - bug\nassignees: {{owner}}\n---\n" +
+				"## Summary\n\n{{description}}\n\n## Details\n\n1. {{steps}}\n\n---\nFiled by {{owner}}\n",
+		},
+		{
+			name: "Template extending a template that extends another",
+			file: "urgent/bug.md",
+			expected: "---\ntitle: \"[URGENT] {{title}}\"\nlabels:\n  - bug\nassignees: {{owner}}\n---\n" +
+				"## Summary\n\n**Urgent**: {{description}}\n\n## Details\n\n1. {{steps}}\n\n---\nFiled by {{owner}}\n",
+		},
+		{
+			name:     "Partial including a partial relative to itself",
+			file:     "include.md",
+			expected: "---\ntitle: {{title}}\n---\n---\nFiled by {{owner}}",
+		},
+		{
+			name:     "Template without front matter",
+			file:     "no_frontmatter.md",
+			expected: "Just Filed by {{owner}}",
+		},
+		{name: "Text outside of blocks", file: "outside.md", err: "outside.md: text outside of blocks"},
+		{name: "Unknown block", file: "unknown.md", err: "unknown.md: block 'nothing' is not defined in base.md"},
+		{name: "Unclosed block", file: "unclosed.md", err: "block 'summary' is not closed with {{/summary}}"},
+		{name: "Missing partial", file: "missing.md", err: "missing.md: failed to read partial nowhere.md"},
+		{name: "Include cycle", file: "loop/a.md", err: "include cycle: loop/a.md -> loop/b.md -> loop/a.md"},
+		{name: "Template extending itself", file: "loop/self.md", err: "extends cycle: loop/self.md -> loop/self.md"},
+		{name: "Extends cycle", file: "loop/child.md", err: "extends cycle: loop/child.md -> loop/parent.md -> loop/child.md"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := composer.Compose(tc.file, catalog[tc.file])
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Fatalf("Expected error containing %q, got: %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("Expected:\n%q\nGot:\n%q", tc.expected, result)
+			}
+		})
+	}
+}
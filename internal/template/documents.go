@@ -0,0 +1,69 @@
+package template
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DocumentMarker is a line separating the documents of a template file that
+// creates several issues from each row. Each document has its own front
+// matter and body.
+const DocumentMarker = "<!-- issue -->"
+
+// SplitDocuments splits a template file into its documents at lines holding
+// only DocumentMarker. Blank documents, such as after a trailing marker, are
+// dropped.
+func SplitDocuments(content string) []string {
+	var documents []string
+	var current strings.Builder
+	flush := func() {
+		document := strings.TrimLeft(current.String(), "\r\n")
+		if strings.TrimSpace(document) != "" {
+			documents = append(documents, document)
+		}
+		current.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(content, "\n") {
+		if strings.TrimSpace(line) == DocumentMarker {
+			flush()
+			continue
+		}
+		current.WriteString(line)
+	}
+	flush()
+	return documents
+}
+
+// ExtractCondition removes the when field from the front matter of a
+// document and returns the document without it along with the condition
+// as written, or "" if it has none. Conditions are --where expressions,
+// parsed by the caller.
+func ExtractCondition(content string) (string, string, error) {
+	frontMatter, body, ok := splitTemplate(content)
+	if !ok {
+		return content, "", nil
+	}
+
+	fields := splitFrontMatterFields(frontMatter)
+	for i, field := range fields {
+		if field.key != "when" {
+			continue
+		}
+		// Decoded as YAML so that quoted conditions may use escapes
+		var decoded struct {
+			When string `yaml:"when"`
+		}
+		if err := yaml.Unmarshal([]byte(field.text), &decoded); err != nil {
+			return "", "", fmt.Errorf("invalid when: %v", err)
+		}
+		if strings.TrimSpace(decoded.When) == "" {
+			return "", "", fmt.Errorf("when is empty")
+		}
+		fields = append(fields[:i], fields[i+1:]...)
+		return "---\n" + mergeFrontMatter(fields, nil) + "---\n" + body, decoded.When, nil
+	}
+	return content, "", nil
+}
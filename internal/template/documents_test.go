@@ -0,0 +1,91 @@
+package template
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitDocuments(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name:     "Single document",
+			content:  "---\ntitle: A\n---\nBody\n",
+			expected: []string{"---\ntitle: A\n---\nBody\n"},
+		},
+		{
+			name:     "Several documents",
+			content:  "---\ntitle: A\n---\nBody A\n\n<!-- issue -->\n---\ntitle: B\n---\nBody B\n",
+			expected: []string{"---\ntitle: A\n---\nBody A\n\n", "---\ntitle: B\n---\nBody B\n"},
+		},
+		{
+			name:     "Leading and trailing markers",
+			content:  "<!-- issue -->\n---\ntitle: A\n---\nA\n  <!-- issue -->  \n\n",
+			expected: []string{"---\ntitle: A\n---\nA\n"},
+		},
+		{
+			name:     "Marker within a line",
+			content:  "---\ntitle: A\n---\nText <!-- issue --> text\n",
+			expected: []string{"---\ntitle: A\n---\nText <!-- issue --> text\n"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			documents := SplitDocuments(tc.content)
+			if !reflect.DeepEqual(documents, tc.expected) {
+				t.Errorf("Expected %q, got %q", tc.expected, documents)
+			}
+		})
+	}
+}
+
+func TestExtractCondition(t *testing.T) {
+	content, when, err := ExtractCondition("---\ntitle: Laptop\nwhen: \"!remote && laptop\"\nlabels:\n  - it\n---\nOrder a laptop\n")
+	if err != nil || when != "!remote && laptop" {
+		t.Errorf("Expected condition '!remote && laptop', got %q, %v", when, err)
+	}
+	if content != "---\ntitle: Laptop\nlabels:\n  - it\n---\nOrder a laptop\n" {
+		t.Errorf("Expected when to be removed, got %q", content)
+	}
+
+	content, when, err = ExtractCondition("---\ntitle: Accounts\n---\nBody")
+	if err != nil || when != "" || content != "---\ntitle: Accounts\n---\nBody" {
+		t.Errorf("Expected document without condition unchanged, got %q, %q, %v", content, when, err)
+	}
+
+	// Conditions are YAML scalars, so quoted ones may use escapes
+	testCases := []struct {
+		name     string
+		field    string
+		expected string
+		err      string
+	}{
+		{name: "Plain", field: `when: role == "engineer"`, expected: `role == "engineer"`},
+		{name: "Double quoted with escapes", field: `when: "priority == \"P1\""`, expected: `priority == "P1"`},
+		{name: "Single quoted", field: `when: 'id =~ "^\d+$" && owner != ''bot'''`, expected: `id =~ "^\d+$" && owner != 'bot'`},
+		{name: "Empty", field: "when:", err: "when is empty"},
+		{name: "Not a scalar", field: "when:\n  - a", err: "invalid when"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			content, when, err := ExtractCondition("---\ntitle: Review\n" + tc.field + "\n---\nBody")
+			if tc.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.err) {
+					t.Errorf("Expected error containing %q, got %q, %v", tc.err, when, err)
+				}
+				return
+			}
+			if err != nil || when != tc.expected {
+				t.Errorf("Expected %q, got %q, %v", tc.expected, when, err)
+			}
+			if content != "---\ntitle: Review\n---\nBody" {
+				t.Errorf("Expected when to be removed, got %q", content)
+			}
+		})
+	}
+}
@@ -0,0 +1,331 @@
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// noResponse is what GitHub writes for form fields left blank
+const noResponse = "_No response_"
+
+// Form is a GitHub issue form (.github/ISSUE_TEMPLATE/*.yml)
+type Form struct {
+	Name        string      `yaml:"name"`
+	Description string      `yaml:"description"`
+	Title       string      `yaml:"title"`
+	Labels      interface{} `yaml:"labels"`
+	Assignees   interface{} `yaml:"assignees"`
+	Type        string      `yaml:"type"`
+	Body        []FormField `yaml:"body"`
+}
+
+// FormField is an element of an issue form body
+type FormField struct {
+	Type        string         `yaml:"type"`
+	ID          string         `yaml:"id"`
+	Attributes  FormAttributes `yaml:"attributes"`
+	Validations struct {
+		Required bool `yaml:"required"`
+	} `yaml:"validations"`
+}
+
+// FormAttributes holds the attributes of a form field. Not every attribute
+// applies to every field type.
+type FormAttributes struct {
+	Label       string       `yaml:"label"`
+	Description string       `yaml:"description"`
+	Placeholder string       `yaml:"placeholder"`
+	Value       string       `yaml:"value"`
+	Render      string       `yaml:"render"`
+	Multiple    bool         `yaml:"multiple"`
+	Default     *int         `yaml:"default"`
+	Options     []FormOption `yaml:"options"`
+}
+
+// FormOption is a dropdown option or a checkbox
+type FormOption struct {
+	Label    string
+	Required bool
+}
+
+// UnmarshalYAML reads a dropdown option given as a string or a checkbox
+// given as a mapping with label and required
+func (o *FormOption) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		o.Label = node.Value
+		return nil
+	}
+	var option struct {
+		Label    string `yaml:"label"`
+		Required bool   `yaml:"required"`
+	}
+	if err := node.Decode(&option); err != nil {
+		return err
+	}
+	o.Label, o.Required = option.Label, option.Required
+	return nil
+}
+
+// IsFormFile reports whether path names an issue form rather than a Markdown template
+func IsFormFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yml" || ext == ".yaml"
+}
+
+// ParseForm parses an issue form
+func ParseForm(content []byte) (*Form, error) {
+	var form Form
+	if err := yaml.Unmarshal(content, &form); err != nil {
+		return nil, fmt.Errorf("failed to parse issue form: %v", err)
+	}
+	if len(form.Body) == 0 {
+		return nil, fmt.Errorf("issue form has no body")
+	}
+
+	for i, field := range form.Body {
+		switch field.Type {
+		case "markdown":
+			continue
+		case "input", "textarea", "dropdown", "checkboxes":
+		default:
+			return nil, fmt.Errorf("issue form field %d has unsupported type '%s'", i+1, field.Type)
+		}
+		if field.Column() == "" {
+			return nil, fmt.Errorf("issue form field %d has neither an id nor a label", i+1)
+		}
+		if (field.Type == "dropdown" || field.Type == "checkboxes") && len(field.Attributes.Options) == 0 {
+			return nil, fmt.Errorf("issue form field '%s' has no options", field.Column())
+		}
+	}
+
+	return &form, nil
+}
+
+// Column returns the CSV column that fills the field: its id, or its label
+// when it has no id
+func (f FormField) Column() string {
+	if f.ID != "" {
+		return f.ID
+	}
+	return f.Attributes.Label
+}
+
+// Template converts the form into a Markdown template whose front matter
+// carries the form's title, labels, assignees and type and whose body has a
+// "### Label" section per field filled from the field's column. Row data must
+// be passed through Prepare before rendering it.
+func (f *Form) Template() string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	fmt.Fprintf(&b, "title: %s\n", quoteYAML(f.Title+"{{title}}"))
+	if labels := parseList(f.Labels); len(labels) > 0 {
+		fmt.Fprintf(&b, "labels: %s\n", quoteYAML(labels))
+	}
+	if assignees := parseList(f.Assignees); len(assignees) > 0 {
+		fmt.Fprintf(&b, "assignees: %s\n", quoteYAML(assignees))
+	}
+	if f.Type != "" {
+		fmt.Fprintf(&b, "type: %s\n", quoteYAML(f.Type))
+	}
+	b.WriteString("---\n")
+
+	for _, field := range f.Body {
+		if field.Type == "markdown" {
+			continue
+		}
+		fmt.Fprintf(&b, "### %s\n\n{{%s}}\n\n", field.Attributes.Label, field.Column())
+	}
+	return b.String()
+}
+
+// Prepare converts row data into the values Template expects: blank fields
+// become their default or "_No response_", dropdown values are checked
+// against the options, checkboxes become task lists, textareas with a
+// render language become code blocks and the title is escaped for the
+// quoted front matter value. It returns an error for a required
+// field or checkbox that is not filled in.
+func (f *Form) Prepare(data map[string]string) (map[string]string, error) {
+	prepared := make(map[string]string, len(data))
+	for key, value := range data {
+		prepared[key] = value
+	}
+
+	for _, field := range f.Body {
+		column := field.Column()
+		value := strings.TrimSpace(data[column])
+		attrs := field.Attributes
+
+		switch field.Type {
+		case "markdown":
+			continue
+		case "input", "textarea":
+			if value == "" {
+				value = strings.TrimSpace(attrs.Value)
+			}
+			if value != "" && field.Type == "textarea" && attrs.Render != "" {
+				value = fmt.Sprintf("```%s\n%s\n```", attrs.Render, value)
+			}
+		case "dropdown":
+			selected, err := field.selectOptions(value)
+			if err != nil {
+				return nil, err
+			}
+			value = strings.Join(selected, ", ")
+		case "checkboxes":
+			checked, err := field.checkOptions(value)
+			if err != nil {
+				return nil, err
+			}
+			var lines []string
+			for _, option := range attrs.Options {
+				mark := " "
+				if checked[option.Label] {
+					mark = "x"
+				}
+				lines = append(lines, fmt.Sprintf("- [%s] %s", mark, option.Label))
+			}
+			prepared[column] = strings.Join(lines, "\n")
+			continue
+		}
+
+		if value == "" {
+			if field.Validations.Required {
+				return nil, fmt.Errorf("column '%s': '%s' is required", column, attrs.Label)
+			}
+			value = noResponse
+		}
+		prepared[column] = value
+	}
+
+	// The title fills a quoted front matter string, so quotes, backslashes
+	// and line breaks must be escaped to keep the YAML valid
+	if title, ok := prepared["title"]; ok {
+		quoted := quoteYAML(title)
+		prepared["title"] = quoted[1 : len(quoted)-1]
+	}
+
+	return prepared, nil
+}
+
+// VariableDocs documents the columns of the form with the label and
+// description of each field and its placeholder as the example
+func (f *Form) VariableDocs() map[string]VariableDoc {
+	docs := map[string]VariableDoc{
+		"title": {Description: "Issue title"},
+	}
+	if f.Title != "" {
+		docs["title"] = VariableDoc{Description: fmt.Sprintf("Issue title, after the prefix %q", f.Title)}
+	}
+
+	for _, field := range f.Body {
+		if field.Type == "markdown" {
+			continue
+		}
+		description := field.Attributes.Label
+		if field.Attributes.Description != "" {
+			description += " - " + field.Attributes.Description
+		}
+		switch field.Type {
+		case "dropdown", "checkboxes":
+			var options []string
+			for _, option := range field.Attributes.Options {
+				options = append(options, option.Label)
+			}
+			description += fmt.Sprintf(" (%s)", strings.Join(options, ", "))
+		}
+		if field.Validations.Required {
+			description += " [required]"
+		}
+		docs[field.Column()] = VariableDoc{Description: description, Example: field.Attributes.Placeholder}
+	}
+	return docs
+}
+
+// selectOptions returns the dropdown options named by value, which lists
+// several options separated by commas when the dropdown allows multiple
+func (f FormField) selectOptions(value string) ([]string, error) {
+	if value == "" {
+		if f.Attributes.Default != nil && *f.Attributes.Default >= 0 && *f.Attributes.Default < len(f.Attributes.Options) {
+			return []string{f.Attributes.Options[*f.Attributes.Default].Label}, nil
+		}
+		return nil, nil
+	}
+
+	values := []string{value}
+	if f.Attributes.Multiple {
+		values = splitList(value)
+	}
+
+	var selected []string
+	for _, v := range values {
+		option, ok := f.findOption(v)
+		if !ok {
+			return nil, fmt.Errorf("column '%s': '%s' is not an option of '%s'", f.Column(), v, f.Attributes.Label)
+		}
+		selected = append(selected, option)
+	}
+	return selected, nil
+}
+
+// checkOptions returns the checkboxes checked by value, which lists option
+// labels separated by newlines or commas. A single checkbox is also checked
+// by "x", "yes" or "true".
+func (f FormField) checkOptions(value string) (map[string]bool, error) {
+	checked := make(map[string]bool)
+	if value != "" {
+		if len(f.Attributes.Options) == 1 && isChecked(value) {
+			checked[f.Attributes.Options[0].Label] = true
+		} else {
+			separator := ","
+			if strings.Contains(value, "\n") {
+				separator = "\n"
+			}
+			for _, v := range strings.Split(value, separator) {
+				if v = strings.TrimSpace(v); v == "" {
+					continue
+				}
+				option, ok := f.findOption(v)
+				if !ok {
+					return nil, fmt.Errorf("column '%s': '%s' is not an option of '%s'", f.Column(), v, f.Attributes.Label)
+				}
+				checked[option] = true
+			}
+		}
+	}
+
+	for _, option := range f.Attributes.Options {
+		if option.Required && !checked[option.Label] {
+			return nil, fmt.Errorf("column '%s': '%s' must be checked", f.Column(), option.Label)
+		}
+	}
+	return checked, nil
+}
+
+// findOption returns the option matching value, ignoring case
+func (f FormField) findOption(value string) (string, bool) {
+	for _, option := range f.Attributes.Options {
+		if strings.EqualFold(option.Label, strings.TrimSpace(value)) {
+			return option.Label, true
+		}
+	}
+	return "", false
+}
+
+// isChecked reports whether value marks a single checkbox as checked
+func isChecked(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "x", "yes", "true":
+		return true
+	}
+	return false
+}
+
+// quoteYAML writes value as JSON, which YAML reads as a quoted scalar or flow sequence
+func quoteYAML(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return string(data)
+}
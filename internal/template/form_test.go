@@ -0,0 +1,200 @@
+package template
+
+import (
+	"reflect"
+	"testing"
+)
+
+const bugForm = `name: Bug report
+description: File a bug report
+title: "[Bug]: "
+labels: ["bug", "triage"]
+assignees: octocat
+type: Bug
+body:
+  - type: markdown
+    attributes:
+      value: Thanks for taking the time to fill out this bug report!
+  - type: input
+    id: contact
+    attributes:
+      label: Contact details
+      placeholder: ex. email@example.com
+  - type: textarea
+    id: what-happened
+    attributes:
+      label: What happened?
+    validations:
+      required: true
+  - type: dropdown
+    id: browsers
+    attributes:
+      label: Browsers
+      multiple: true
+      options:
+        - Firefox
+        - Chrome
+        - Safari
+  - type: dropdown
+    id: version
+    attributes:
+      label: Version
+      options: ["1.0", "2.0"]
+      default: 1
+  - type: textarea
+    id: logs
+    attributes:
+      label: Relevant log output
+      render: shell
+  - type: checkboxes
+    id: terms
+    attributes:
+      label: Code of Conduct
+      options:
+        - label: I agree to follow this project's Code of Conduct
+          required: true
+        - label: I searched existing issues
+`
+
+func TestParseFormAndRender(t *testing.T) {
+	form, err := ParseForm([]byte(bugForm))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	renderer := NewRenderer()
+	tmplContent := form.Template()
+
+	expectedVars := []string{"title", "contact", "what-happened", "browsers", "version", "logs", "terms"}
+	if vars := renderer.ExtractVariables(tmplContent); !reflect.DeepEqual(vars, expectedVars) {
+		t.Errorf("Expected variables %v, got %v", expectedVars, vars)
+	}
+
+	data, err := form.Prepare(map[string]string{
+		"title":         "Crash on save",
+		"what-happened": "It crashed",
+		"browsers":      "firefox, Safari",
+		"logs":          "panic: nil map",
+		"terms":         "I agree to follow this project's Code of Conduct",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	rendered, err := renderer.Render(tmplContent, data)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	issue, err := NewParser().ParseIssueTemplate(rendered)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if issue.Title != "[Bug]: Crash on save" {
+		t.Errorf("Expected title with form prefix, got %q", issue.Title)
+	}
+	if !reflect.DeepEqual(issue.Labels, []string{"bug", "triage"}) || !reflect.DeepEqual(issue.Assignees, []string{"octocat"}) {
+		t.Errorf("Expected form labels and assignees, got %v and %v", issue.Labels, issue.Assignees)
+	}
+	if issue.Type != "Bug" {
+		t.Errorf("Expected type Bug, got %q", issue.Type)
+	}
+
+	expectedBody := "### Contact details\n\n_No response_\n\n" +
+		"### What happened?\n\nIt crashed\n\n" +
+		"### Browsers\n\nFirefox, Safari\n\n" +
+		"### Version\n\n2.0\n\n" +
+		"### Relevant log output\n\n```shell\npanic: nil map\n```\n\n" +
+		"### Code of Conduct\n\n- [x] I agree to follow this project's Code of Conduct\n- [ ] I searched existing issues"
+	if issue.Body != expectedBody {
+		t.Errorf("Expected body:\n%s\ngot:\n%s", expectedBody, issue.Body)
+	}
+}
+
+func TestFormTitleWithQuotes(t *testing.T) {
+	form, err := ParseForm([]byte(bugForm))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	title := `Save fails on "C:\data" <again>`
+	data, err := form.Prepare(map[string]string{"title": title, "what-happened": "It crashed", "terms": "I agree to follow this project's Code of Conduct"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	rendered, err := NewRenderer().Render(form.Template(), data)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	issue, err := NewParser().ParseIssueTemplate(rendered)
+	if err != nil {
+		t.Fatalf("Expected the title to keep the front matter valid, got: %v", err)
+	}
+	if issue.Title != "[Bug]: "+title {
+		t.Errorf("Expected title %q, got %q", "[Bug]: "+title, issue.Title)
+	}
+}
+
+func TestFormPrepareErrors(t *testing.T) {
+	form, err := ParseForm([]byte(bugForm))
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	terms := "I agree to follow this project's Code of Conduct"
+
+	testCases := []struct {
+		name     string
+		data     map[string]string
+		expected string
+	}{
+		{
+			name:     "Required field is blank",
+			data:     map[string]string{"terms": terms},
+			expected: "column 'what-happened': 'What happened?' is required",
+		},
+		{
+			name:     "Required checkbox is not checked",
+			data:     map[string]string{"what-happened": "x", "terms": "I searched existing issues"},
+			expected: "column 'terms': 'I agree to follow this project's Code of Conduct' must be checked",
+		},
+		{
+			name:     "Unknown dropdown option",
+			data:     map[string]string{"what-happened": "x", "version": "3.0", "terms": terms},
+			expected: "column 'version': '3.0' is not an option of 'Version'",
+		},
+		{
+			name:     "Unknown checkbox",
+			data:     map[string]string{"what-happened": "x", "terms": terms + "\nI read the docs"},
+			expected: "column 'terms': 'I read the docs' is not an option of 'Code of Conduct'",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := form.Prepare(tc.data)
+			if err == nil || err.Error() != tc.expected {
+				t.Errorf("Expected error %q, got: %v", tc.expected, err)
+			}
+		})
+	}
+}
+
+func TestParseFormErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{name: "No body", content: "name: Empty\n"},
+		{name: "Unknown field type", content: "body:\n  - type: slider\n    id: level\n"},
+		{name: "Dropdown without options", content: "body:\n  - type: dropdown\n    id: version\n    attributes:\n      label: Version\n"},
+		{name: "Field without id or label", content: "body:\n  - type: input\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseForm([]byte(tc.content)); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package template
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Matrix describes how a single CSV row is expanded into several issues.
+// It follows the semantics of GitHub Actions matrices: the cartesian product
+// of all axes is computed, exclude rules remove combinations, and include
+// rules extend matching combinations or add new ones.
+type Matrix struct {
+	Axes    []MatrixAxis
+	Include []map[string]string
+	Exclude []map[string]string
+}
+
+// MatrixAxis is a named list of values
+type MatrixAxis struct {
+	Name   string
+	Values []string
+}
+
+// IsEmpty reports whether the matrix defines no combinations at all
+func (m *Matrix) IsEmpty() bool {
+	return m == nil || (len(m.Axes) == 0 && len(m.Include) == 0)
+}
+
+// Keys returns every variable name the matrix can set, in definition order
+func (m *Matrix) Keys() []string {
+	if m == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var keys []string
+	add := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	for _, axis := range m.Axes {
+		add(axis.Name)
+	}
+	for _, include := range m.Include {
+		for _, key := range sortedKeys(include) {
+			add(key)
+		}
+	}
+	return keys
+}
+
+// Merge adds the axes, includes and excludes of other to the matrix.
+// Axes in other replace axes of the same name.
+func (m *Matrix) Merge(other *Matrix) *Matrix {
+	if other == nil {
+		return m
+	}
+	if m == nil {
+		m = &Matrix{}
+	}
+
+	merged := &Matrix{}
+	for _, axis := range m.Axes {
+		if other.axis(axis.Name) == nil {
+			merged.Axes = append(merged.Axes, axis)
+		}
+	}
+	merged.Axes = append(merged.Axes, other.Axes...)
+	merged.Include = append(append(merged.Include, m.Include...), other.Include...)
+	merged.Exclude = append(append(merged.Exclude, m.Exclude...), other.Exclude...)
+	return merged
+}
+
+// Expand returns every combination of the matrix as variable maps
+func (m *Matrix) Expand() ([]map[string]string, error) {
+	if m.IsEmpty() {
+		return nil, nil
+	}
+
+	// Cartesian product of all axes
+	combinations := []map[string]string{{}}
+	for _, axis := range m.Axes {
+		if len(axis.Values) == 0 {
+			return nil, fmt.Errorf("matrix axis '%s' has no values", axis.Name)
+		}
+		var next []map[string]string
+		for _, combination := range combinations {
+			for _, value := range axis.Values {
+				expanded := copyVariables(combination)
+				expanded[axis.Name] = value
+				next = append(next, expanded)
+			}
+		}
+		combinations = next
+	}
+	if len(m.Axes) == 0 {
+		combinations = nil
+	}
+
+	// Remove excluded combinations
+	if len(m.Exclude) > 0 {
+		var kept []map[string]string
+		for _, combination := range combinations {
+			excluded := false
+			for _, exclude := range m.Exclude {
+				if matchesAll(combination, exclude) {
+					excluded = true
+					break
+				}
+			}
+			if !excluded {
+				kept = append(kept, combination)
+			}
+		}
+		combinations = kept
+	}
+
+	// Apply include rules. An include extends every combination whose axis
+	// values it does not contradict, otherwise it becomes a new combination.
+	for _, include := range m.Include {
+		extended := false
+		for _, combination := range combinations {
+			if m.compatible(combination, include) {
+				for key, value := range include {
+					combination[key] = value
+				}
+				extended = true
+			}
+		}
+		if !extended {
+			combinations = append(combinations, copyVariables(include))
+		}
+	}
+
+	return combinations, nil
+}
+
+// compatible reports whether include only sets axis values that match the combination
+func (m *Matrix) compatible(combination, include map[string]string) bool {
+	for key, value := range include {
+		if m.axis(key) == nil {
+			continue
+		}
+		if existing, ok := combination[key]; ok && existing != value {
+			return false
+		}
+	}
+	return true
+}
+
+// axis finds an axis by name
+func (m *Matrix) axis(name string) *MatrixAxis {
+	for i := range m.Axes {
+		if m.Axes[i].Name == name {
+			return &m.Axes[i]
+		}
+	}
+	return nil
+}
+
+// ParseMatrix extracts the matrix block from the front matter of a rendered
+// template. It returns nil if the front matter has no matrix.
+func (p *Parser) ParseMatrix(content string) (*Matrix, error) {
+	if !strings.HasPrefix(content, "---") {
+		return nil, nil
+	}
+
+	parts := strings.SplitN(content, "---", 3)
+	if len(parts) < 3 {
+		return nil, nil
+	}
+
+	var frontMatter struct {
+		Matrix yaml.Node `yaml:"matrix"`
+	}
+	if err := yaml.Unmarshal([]byte(parts[1]), &frontMatter); err != nil {
+		return nil, fmt.Errorf("failed to parse front matter: %v", err)
+	}
+
+	node := &frontMatter.Matrix
+	if node.Kind == 0 {
+		return nil, nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("matrix must be a mapping of names to value lists")
+	}
+
+	matrix := &Matrix{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name := node.Content[i].Value
+		value := node.Content[i+1]
+
+		switch name {
+		case "include", "exclude":
+			rules, err := parseMatrixRules(name, value)
+			if err != nil {
+				return nil, err
+			}
+			if name == "include" {
+				matrix.Include = rules
+			} else {
+				matrix.Exclude = rules
+			}
+		default:
+			values, err := parseMatrixValues(name, value)
+			if err != nil {
+				return nil, err
+			}
+			matrix.Axes = append(matrix.Axes, MatrixAxis{Name: name, Values: values})
+		}
+	}
+
+	return matrix, nil
+}
+
+// ParseMatrixFlag parses a command line matrix axis in NAME=VALUE1,VALUE2 form
+func ParseMatrixFlag(value string) (MatrixAxis, error) {
+	name, values, ok := strings.Cut(value, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return MatrixAxis{}, fmt.Errorf("invalid matrix '%s': expected NAME=VALUE1,VALUE2", value)
+	}
+	return MatrixAxis{Name: name, Values: splitList(values)}, nil
+}
+
+// parseMatrixValues reads an axis given as a YAML sequence or a comma-separated string
+func parseMatrixValues(name string, node *yaml.Node) ([]string, error) {
+	switch node.Kind {
+	case yaml.SequenceNode:
+		values := make([]string, 0, len(node.Content))
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("matrix axis '%s' must contain only scalar values", name)
+			}
+			values = append(values, item.Value)
+		}
+		return values, nil
+	case yaml.ScalarNode:
+		return splitList(node.Value), nil
+	default:
+		return nil, fmt.Errorf("matrix axis '%s' must be a list or a comma-separated string", name)
+	}
+}
+
+// parseMatrixRules reads an include or exclude list of variable maps
+func parseMatrixRules(name string, node *yaml.Node) ([]map[string]string, error) {
+	if node.Kind != yaml.SequenceNode {
+		return nil, fmt.Errorf("matrix %s must be a list of mappings", name)
+	}
+
+	var rules []map[string]string
+	for _, item := range node.Content {
+		var rule map[string]string
+		if err := item.Decode(&rule); err != nil {
+			return nil, fmt.Errorf("invalid matrix %s entry: %v", name, err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// splitList splits a comma-separated string, dropping empty items
+func splitList(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// matchesAll reports whether every key in rule has the same value in combination
+func matchesAll(combination, rule map[string]string) bool {
+	for key, value := range rule {
+		if combination[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// copyVariables returns a shallow copy of a variable map
+func copyVariables(variables map[string]string) map[string]string {
+	copied := make(map[string]string, len(variables))
+	for key, value := range variables {
+		copied[key] = value
+	}
+	return copied
+}
+
+// sortedKeys returns the keys of a variable map in sorted order
+func sortedKeys(variables map[string]string) []string {
+	keys := make([]string, 0, len(variables))
+	for key := range variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,224 @@
+package template
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMatrix(t *testing.T) {
+	testCases := []struct {
+		name          string
+		content       string
+		expected      *Matrix
+		expectedError bool
+	}{
+		{
+			name:     "No matrix",
+			content:  "---\ntitle: Test\n---\nBody",
+			expected: nil,
+		},
+		{
+			name:     "No front matter",
+			content:  "Body",
+			expected: nil,
+		},
+		{
+			name: "Lists and comma-separated strings",
+			content: `---
+title: Test
+matrix:
+  service: "api, web"
+  env:
+    - staging
+    - production
+---
+Body`,
+			expected: &Matrix{
+				Axes: []MatrixAxis{
+					{Name: "service", Values: []string{"api", "web"}},
+					{Name: "env", Values: []string{"staging", "production"}},
+				},
+			},
+		},
+		{
+			name: "Include and exclude",
+			content: `---
+matrix:
+  env: [staging, production]
+  include:
+    - env: production
+      owner: ops
+  exclude:
+    - env: staging
+---
+Body`,
+			expected: &Matrix{
+				Axes:    []MatrixAxis{{Name: "env", Values: []string{"staging", "production"}}},
+				Include: []map[string]string{{"env": "production", "owner": "ops"}},
+				Exclude: []map[string]string{{"env": "staging"}},
+			},
+		},
+		{
+			name:          "Matrix is not a mapping",
+			content:       "---\nmatrix: [a, b]\n---\nBody",
+			expectedError: true,
+		},
+		{
+			name:          "Include is not a list",
+			content:       "---\nmatrix:\n  include: value\n---\nBody",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := NewParser()
+			matrix, err := parser.ParseMatrix(tc.content)
+
+			if tc.expectedError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if !reflect.DeepEqual(matrix, tc.expected) {
+				t.Errorf("Expected matrix %+v, got %+v", tc.expected, matrix)
+			}
+		})
+	}
+}
+
+func TestMatrixExpand(t *testing.T) {
+	testCases := []struct {
+		name          string
+		matrix        *Matrix
+		expected      []map[string]string
+		expectedError bool
+	}{
+		{
+			name:     "Empty matrix",
+			matrix:   nil,
+			expected: nil,
+		},
+		{
+			name: "Cartesian product",
+			matrix: &Matrix{Axes: []MatrixAxis{
+				{Name: "service", Values: []string{"api", "web"}},
+				{Name: "env", Values: []string{"staging", "production"}},
+			}},
+			expected: []map[string]string{
+				{"service": "api", "env": "staging"},
+				{"service": "api", "env": "production"},
+				{"service": "web", "env": "staging"},
+				{"service": "web", "env": "production"},
+			},
+		},
+		{
+			name: "Exclude removes matching combinations",
+			matrix: &Matrix{
+				Axes: []MatrixAxis{
+					{Name: "service", Values: []string{"api", "web"}},
+					{Name: "env", Values: []string{"staging", "production"}},
+				},
+				Exclude: []map[string]string{{"service": "web", "env": "staging"}},
+			},
+			expected: []map[string]string{
+				{"service": "api", "env": "staging"},
+				{"service": "api", "env": "production"},
+				{"service": "web", "env": "production"},
+			},
+		},
+		{
+			name: "Include extends matching combinations and adds new ones",
+			matrix: &Matrix{
+				Axes: []MatrixAxis{{Name: "env", Values: []string{"staging", "production"}}},
+				Include: []map[string]string{
+					{"env": "production", "owner": "ops"},
+					{"env": "dev", "owner": "team"},
+				},
+			},
+			expected: []map[string]string{
+				{"env": "staging"},
+				{"env": "production", "owner": "ops"},
+				{"env": "dev", "owner": "team"},
+			},
+		},
+		{
+			name: "Include only",
+			matrix: &Matrix{
+				Include: []map[string]string{{"env": "production"}},
+			},
+			expected: []map[string]string{{"env": "production"}},
+		},
+		{
+			name:          "Axis without values",
+			matrix:        &Matrix{Axes: []MatrixAxis{{Name: "env"}}},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.matrix.Expand()
+
+			if tc.expectedError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("Expected combinations %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestMatrixMergeAndKeys(t *testing.T) {
+	base := &Matrix{
+		Axes:    []MatrixAxis{{Name: "env", Values: []string{"staging"}}, {Name: "service", Values: []string{"api"}}},
+		Include: []map[string]string{{"owner": "ops"}},
+	}
+	cli := &Matrix{Axes: []MatrixAxis{{Name: "env", Values: []string{"production"}}}}
+
+	merged := base.Merge(cli)
+
+	expectedAxes := []MatrixAxis{{Name: "service", Values: []string{"api"}}, {Name: "env", Values: []string{"production"}}}
+	if !reflect.DeepEqual(merged.Axes, expectedAxes) {
+		t.Errorf("Expected axes %v, got %v", expectedAxes, merged.Axes)
+	}
+
+	expectedKeys := []string{"service", "env", "owner"}
+	if !reflect.DeepEqual(merged.Keys(), expectedKeys) {
+		t.Errorf("Expected keys %v, got %v", expectedKeys, merged.Keys())
+	}
+
+	var empty *Matrix
+	if empty.Merge(nil) != nil {
+		t.Error("Expected merging two nil matrices to return nil")
+	}
+}
+
+func TestParseMatrixFlag(t *testing.T) {
+	axis, err := ParseMatrixFlag("env=staging, production")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := MatrixAxis{Name: "env", Values: []string{"staging", "production"}}
+	if !reflect.DeepEqual(axis, expected) {
+		t.Errorf("Expected axis %v, got %v", expected, axis)
+	}
+
+	if _, err := ParseMatrixFlag("staging,production"); err == nil {
+		t.Error("Expected error for missing name, got nil")
+	}
+}
@@ -4,12 +4,31 @@ package template
 
 import (
 	"fmt"
+	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
 	"gopkg.in/yaml.v3"
 )
 
+// commentSeparator matches a line that starts an additional comment section in the body
+var commentSeparator = regexp.MustCompile(`(?m)^---[ \t]*comment[ \t]*---[ \t]*\r?$`)
+
+// validStateReasons lists the state_reason values accepted for closed issues
+var validStateReasons = map[string]bool{
+	"completed":   true,
+	"not_planned": true,
+}
+
+// validLockReasons lists the lock_reason values accepted by the GitHub API
+var validLockReasons = map[string]bool{
+	"off-topic":  true,
+	"too heated": true,
+	"resolved":   true,
+	"spam":       true,
+}
+
 // Parser provides markdown parsing functionality
 type Parser struct{}
 
@@ -33,7 +52,10 @@ func (p *Parser) ParseIssueTemplate(content string) (*models.Issue, error) {
 	}
 
 	frontMatter := parts[1]
-	body := strings.TrimSpace(parts[2])
+
+	// Split body into the issue body and any trailing comment sections
+	sections := commentSeparator.Split(parts[2], -1)
+	body := strings.TrimSpace(sections[0])
 
 	// Parse front matter as YAML
 	metadata := make(map[string]interface{})
@@ -81,10 +103,198 @@ func (p *Parser) ParseIssueTemplate(content string) (*models.Issue, error) {
 		}
 	}
 
+	// Extract CODEOWNERS path used to add assignees
+	if path, ok := metadata["codeowners_path"].(string); ok {
+		issue.CodeownersPath = strings.TrimSpace(path)
+	}
+
 	// Extract milestone
 	if milestone, ok := metadata["milestone"].(string); ok {
 		issue.Milestone = milestone
 	}
 
+	// Extract target repository
+	if repo, ok := metadata["repo"].(string); ok {
+		issue.Repo = strings.TrimSpace(repo)
+	}
+
+	// Extract issue type
+	if issueType, ok := metadata["type"].(string); ok {
+		issue.Type = strings.TrimSpace(issueType)
+	}
+
+	// Extract discussion category
+	if category, ok := metadata["category"].(string); ok {
+		issue.Category = strings.TrimSpace(category)
+	}
+
+	// Extract project field values
+	if fields, ok := metadata["fields"].(map[string]interface{}); ok {
+		issue.Fields = make(map[string]string, len(fields))
+		for name, value := range fields {
+			if value != nil {
+				issue.Fields[name] = strings.TrimSpace(fmt.Sprint(value))
+			}
+		}
+	}
+
+	// Extract comments from front matter, followed by comment sections in the body
+	if comment, ok := metadata["comments"].(string); ok {
+		if strings.TrimSpace(comment) != "" {
+			issue.Comments = append(issue.Comments, strings.TrimSpace(comment))
+		}
+	} else if commentsArray, ok := metadata["comments"].([]interface{}); ok {
+		for _, comment := range commentsArray {
+			if commentStr, ok := comment.(string); ok && strings.TrimSpace(commentStr) != "" {
+				issue.Comments = append(issue.Comments, strings.TrimSpace(commentStr))
+			}
+		}
+	}
+	for _, section := range sections[1:] {
+		if comment := strings.TrimSpace(section); comment != "" {
+			issue.Comments = append(issue.Comments, comment)
+		}
+	}
+
+	if err := parseIssueState(metadata, &issue); err != nil {
+		return nil, err
+	}
+
+	pool, err := parseAssigneePool(metadata["assignee_pool"])
+	if err != nil {
+		return nil, err
+	}
+	issue.AssigneePool = pool
+
 	return &issue, nil
 }
+
+// parseIssueState extracts state, state_reason, locked, lock_reason and pinned
+// from front matter. Empty values are treated as unset so they can be rendered
+// from optional CSV columns.
+func parseIssueState(metadata map[string]interface{}, issue *models.Issue) error {
+	if state, ok := metadata["state"].(string); ok {
+		state = strings.ToLower(strings.TrimSpace(state))
+		if state != "" && state != "open" && state != "closed" {
+			return fmt.Errorf("invalid state '%s': must be 'open' or 'closed'", state)
+		}
+		issue.State = state
+	}
+
+	if reason, ok := metadata["state_reason"].(string); ok {
+		reason = strings.ToLower(strings.TrimSpace(reason))
+		if reason != "" {
+			if !validStateReasons[reason] {
+				return fmt.Errorf("invalid state_reason '%s': must be 'completed' or 'not_planned'", reason)
+			}
+			if issue.State != "closed" {
+				return fmt.Errorf("state_reason '%s' requires state 'closed'", reason)
+			}
+			issue.StateReason = reason
+		}
+	}
+
+	locked, err := parseBool(metadata["locked"])
+	if err != nil {
+		return fmt.Errorf("invalid locked value: %v", err)
+	}
+	issue.Locked = locked
+
+	if reason, ok := metadata["lock_reason"].(string); ok {
+		reason = strings.ToLower(strings.TrimSpace(reason))
+		if reason != "" {
+			if !validLockReasons[reason] {
+				return fmt.Errorf("invalid lock_reason '%s': must be one of off-topic, too heated, resolved, spam", reason)
+			}
+			issue.LockReason = reason
+			issue.Locked = true
+		}
+	}
+
+	pinned, err := parseBool(metadata["pinned"])
+	if err != nil {
+		return fmt.Errorf("invalid pinned value: %v", err)
+	}
+	issue.Pinned = pinned
+
+	return nil
+}
+
+// parseAssigneePool reads assignee_pool, given either as a list of users
+// (round-robin) or as a mapping with strategy, users and seed
+func parseAssigneePool(value interface{}) (*models.AssigneePool, error) {
+	pool := &models.AssigneePool{Strategy: "round-robin"}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string, []interface{}:
+		pool.Users = parseList(v)
+	case map[string]interface{}:
+		if strategy, ok := v["strategy"].(string); ok && strings.TrimSpace(strategy) != "" {
+			pool.Strategy = strings.ToLower(strings.TrimSpace(strategy))
+		}
+		pool.Users = parseList(v["users"])
+		switch seed := v["seed"].(type) {
+		case nil:
+		case int:
+			pool.Seed = int64(seed)
+		case string:
+			if strings.TrimSpace(seed) != "" {
+				parsed, err := strconv.ParseInt(strings.TrimSpace(seed), 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid assignee_pool seed '%s'", seed)
+				}
+				pool.Seed = parsed
+			}
+		default:
+			return nil, fmt.Errorf("invalid assignee_pool seed %v", seed)
+		}
+	default:
+		return nil, fmt.Errorf("assignee_pool must be a list of users or a mapping")
+	}
+
+	// A pool rendered from an empty CSV value is treated as unset
+	if len(pool.Users) == 0 {
+		return nil, nil
+	}
+	return pool, nil
+}
+
+// parseList reads a comma-separated string or a YAML list of strings
+func parseList(value interface{}) []string {
+	var items []string
+	switch v := value.(type) {
+	case string:
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			if itemStr, ok := item.(string); ok && strings.TrimSpace(itemStr) != "" {
+				items = append(items, strings.TrimSpace(itemStr))
+			}
+		}
+	}
+	return items
+}
+
+// parseBool converts a YAML boolean or a string such as "true" into a bool.
+// Missing and empty values are false.
+func parseBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return false, nil
+	case bool:
+		return v, nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return false, nil
+		}
+		return strconv.ParseBool(strings.TrimSpace(v))
+	default:
+		return false, fmt.Errorf("unsupported value %v", v)
+	}
+}
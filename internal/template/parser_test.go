@@ -3,6 +3,8 @@ package template
 import (
 	"reflect"
 	"testing"
+
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
 )
 
 func TestParseIssueTemplate(t *testing.T) {
@@ -102,3 +104,301 @@ Body content`,
 		})
 	}
 }
+
+func TestParseIssueTemplateComments(t *testing.T) {
+	testCases := []struct {
+		name             string
+		content          string
+		expectedBody     string
+		expectedComments []string
+	}{
+		{
+			name: "No comments",
+			content: `---
+title: "Test Issue"
+---
+Body content`,
+			expectedBody:     "Body content",
+			expectedComments: nil,
+		},
+		{
+			name: "Front matter comments",
+			content: `---
+title: "Test Issue"
+comments:
+  - "- [ ] Confirm scope"
+  - Triage note
+---
+Body content`,
+			expectedBody:     "Body content",
+			expectedComments: []string{"- [ ] Confirm scope", "Triage note"},
+		},
+		{
+			name: "Comment sections",
+			content: `---
+title: "Test Issue"
+---
+Body content
+--- comment ---
+First comment
+--- comment ---
+
+Second comment
+`,
+			expectedBody:     "Body content",
+			expectedComments: []string{"First comment", "Second comment"},
+		},
+		{
+			name: "Front matter comments before comment sections",
+			content: `---
+title: "Test Issue"
+comments: From front matter
+---
+Body content
+--- comment ---
+From section`,
+			expectedBody:     "Body content",
+			expectedComments: []string{"From front matter", "From section"},
+		},
+		{
+			name: "Rules around a word are not a comment section",
+			content: `---
+title: "Test Issue"
+---
+Body content
+---
+comment
+---
+More body`,
+			expectedBody:     "Body content\n---\ncomment\n---\nMore body",
+			expectedComments: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := NewParser()
+			issue, err := parser.ParseIssueTemplate(tc.content)
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if issue.Body != tc.expectedBody {
+				t.Errorf("Expected body '%s', got '%s'", tc.expectedBody, issue.Body)
+			}
+
+			if !reflect.DeepEqual(issue.Comments, tc.expectedComments) {
+				t.Errorf("Expected comments %v, got %v", tc.expectedComments, issue.Comments)
+			}
+		})
+	}
+}
+
+func TestParseIssueTemplateRepo(t *testing.T) {
+	parser := NewParser()
+
+	issue, err := parser.ParseIssueTemplate("---\ntitle: Test\nrepo: \" octo/service-a \"\n---\nBody")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if issue.Repo != "octo/service-a" {
+		t.Errorf("Expected repo 'octo/service-a', got '%s'", issue.Repo)
+	}
+
+	issue, err = parser.ParseIssueTemplate("---\ntitle: Test\n---\nBody")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if issue.Repo != "" {
+		t.Errorf("Expected empty repo, got '%s'", issue.Repo)
+	}
+}
+
+func TestParseIssueTemplateCodeownersPath(t *testing.T) {
+	parser := NewParser()
+
+	issue, err := parser.ParseIssueTemplate("---\ntitle: Test\nassignees: \"@octo/payments, alice\"\ncodeowners_path: \" services/payments/refund.go \"\n---\nBody")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if issue.CodeownersPath != "services/payments/refund.go" {
+		t.Errorf("Expected CODEOWNERS path 'services/payments/refund.go', got '%s'", issue.CodeownersPath)
+	}
+	if !reflect.DeepEqual(issue.Assignees, []string{"@octo/payments", "alice"}) {
+		t.Errorf("Expected team mention to be kept for resolution, got %v", issue.Assignees)
+	}
+}
+
+func TestParseIssueTemplateFields(t *testing.T) {
+	parser := NewParser()
+
+	issue, err := parser.ParseIssueTemplate("---\ntitle: Test\nfields:\n  Status: \" Todo \"\n  Estimate: 3\n  Due:\n---\nBody")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	expected := map[string]string{"Status": "Todo", "Estimate": "3"}
+	if !reflect.DeepEqual(issue.Fields, expected) {
+		t.Errorf("Expected fields %v, got %v", expected, issue.Fields)
+	}
+}
+
+func TestParseIssueTemplateType(t *testing.T) {
+	parser := NewParser()
+
+	issue, err := parser.ParseIssueTemplate("---\ntitle: Test\ntype: \" Bug \"\n---\nBody")
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	if issue.Type != "Bug" {
+		t.Errorf("Expected type Bug, got %q", issue.Type)
+	}
+}
+
+func TestParseIssueTemplateAssigneePool(t *testing.T) {
+	testCases := []struct {
+		name          string
+		frontMatter   string
+		expected      *models.AssigneePool
+		expectedError bool
+	}{
+		{
+			name:        "No pool",
+			frontMatter: `title: Test`,
+			expected:    nil,
+		},
+		{
+			name:        "List defaults to round-robin",
+			frontMatter: `assignee_pool: "alice, bob"`,
+			expected:    &models.AssigneePool{Strategy: "round-robin", Users: []string{"alice", "bob"}},
+		},
+		{
+			name: "Mapping with strategy and seed",
+			frontMatter: `assignee_pool:
+  strategy: Random
+  seed: 42
+  users: [alice, bob]`,
+			expected: &models.AssigneePool{Strategy: "random", Users: []string{"alice", "bob"}, Seed: 42},
+		},
+		{
+			name:        "Empty users are unset",
+			frontMatter: `assignee_pool: ""`,
+			expected:    nil,
+		},
+		{
+			name: "Invalid seed",
+			frontMatter: `assignee_pool:
+  users: alice
+  seed: abc`,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := NewParser()
+			issue, err := parser.ParseIssueTemplate("---\n" + tc.frontMatter + "\n---\nBody")
+
+			if tc.expectedError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if !reflect.DeepEqual(issue.AssigneePool, tc.expected) {
+				t.Errorf("Expected pool %+v, got %+v", tc.expected, issue.AssigneePool)
+			}
+		})
+	}
+}
+
+func TestParseIssueTemplateState(t *testing.T) {
+	testCases := []struct {
+		name          string
+		frontMatter   string
+		expected      models.Issue
+		expectedError bool
+	}{
+		{
+			name:        "No state",
+			frontMatter: `title: "Test"`,
+			expected:    models.Issue{},
+		},
+		{
+			name: "Closed as not planned",
+			frontMatter: `state: closed
+state_reason: not_planned`,
+			expected: models.Issue{State: "closed", StateReason: "not_planned"},
+		},
+		{
+			name: "Locked with reason and pinned",
+			frontMatter: `locked: true
+lock_reason: resolved
+pinned: "true"`,
+			expected: models.Issue{Locked: true, LockReason: "resolved", Pinned: true},
+		},
+		{
+			name:        "Lock reason implies locked",
+			frontMatter: `lock_reason: "too heated"`,
+			expected:    models.Issue{Locked: true, LockReason: "too heated"},
+		},
+		{
+			name: "Empty values from CSV are unset",
+			frontMatter: `state: ""
+state_reason: ""
+locked: ""
+pinned: ""`,
+			expected: models.Issue{},
+		},
+		{
+			name:          "Invalid state",
+			frontMatter:   `state: archived`,
+			expectedError: true,
+		},
+		{
+			name:          "State reason without closed state",
+			frontMatter:   `state_reason: completed`,
+			expectedError: true,
+		},
+		{
+			name:          "Invalid lock reason",
+			frontMatter:   `lock_reason: boring`,
+			expectedError: true,
+		},
+		{
+			name:          "Invalid locked value",
+			frontMatter:   `locked: maybe`,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := NewParser()
+			issue, err := parser.ParseIssueTemplate("---\n" + tc.frontMatter + "\n---\nBody")
+
+			if tc.expectedError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if issue.State != tc.expected.State || issue.StateReason != tc.expected.StateReason {
+				t.Errorf("Expected state %q/%q, got %q/%q", tc.expected.State, tc.expected.StateReason, issue.State, issue.StateReason)
+			}
+			if issue.Locked != tc.expected.Locked || issue.LockReason != tc.expected.LockReason {
+				t.Errorf("Expected locked %v/%q, got %v/%q", tc.expected.Locked, tc.expected.LockReason, issue.Locked, issue.LockReason)
+			}
+			if issue.Pinned != tc.expected.Pinned {
+				t.Errorf("Expected pinned %v, got %v", tc.expected.Pinned, issue.Pinned)
+			}
+		})
+	}
+}
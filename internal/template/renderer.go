@@ -4,6 +4,7 @@ package template
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 	"text/template"
 )
@@ -17,24 +18,82 @@ func NewRenderer() *Renderer {
 }
 
 // ExtractVariables extracts all Mustache variable names from a template string
+// in the order they first appear
 func (r *Renderer) ExtractVariables(tmplContent string) []string {
 	re := regexp.MustCompile(`{{([^}]+)}}`)
 	matches := re.FindAllStringSubmatch(tmplContent, -1)
 
-	variableSet := make(map[string]struct{})
+	seen := make(map[string]bool)
+	var variables []string
 	for _, match := range matches {
 		if len(match) > 1 {
 			varName := strings.TrimSpace(match[1])
-			variableSet[varName] = struct{}{}
+			// Section tags such as {{#rows}} and {{/rows}} are not variables
+			if strings.HasPrefix(varName, "#") || strings.HasPrefix(varName, "/") {
+				continue
+			}
+			if !seen[varName] {
+				seen[varName] = true
+				variables = append(variables, varName)
+			}
 		}
 	}
 
-	variables := make([]string, 0, len(variableSet))
-	for varName := range variableSet {
-		variables = append(variables, varName)
+	return variables
+}
+
+// rowsSection matches a {{#rows}}...{{/rows}} section repeated once per grouped row
+var rowsSection = regexp.MustCompile(`(?s){{\s*#rows\s*}}(.*?){{\s*/rows\s*}}`)
+
+// RenderGroup processes a template for a group of rows. Each {{#rows}}...{{/rows}}
+// section is rendered once per row with the row's values on top of data, and the
+// rest of the template is rendered with data.
+func (r *Renderer) RenderGroup(tmplContent string, data map[string]string, rows []map[string]string) (string, error) {
+	// Render sections first and keep them out of the outer template behind
+	// placeholders, so values containing braces are not parsed twice
+	var sections []string
+	var renderErr error
+	tmplContent = rowsSection.ReplaceAllStringFunc(tmplContent, func(section string) string {
+		inner := rowsSection.FindStringSubmatch(section)[1]
+
+		var buf strings.Builder
+		for _, row := range rows {
+			rowData := make(map[string]string, len(data)+len(row))
+			for key, value := range data {
+				rowData[key] = value
+			}
+			for key, value := range row {
+				rowData[key] = value
+			}
+
+			rendered, err := r.Render(inner, rowData)
+			if err != nil && renderErr == nil {
+				renderErr = err
+			}
+			buf.WriteString(rendered)
+		}
+
+		sections = append(sections, buf.String())
+		return sectionPlaceholder(len(sections) - 1)
+	})
+	if renderErr != nil {
+		return "", renderErr
 	}
 
-	return variables
+	result, err := r.Render(tmplContent, data)
+	if err != nil {
+		return "", err
+	}
+
+	for i, section := range sections {
+		result = strings.Replace(result, sectionPlaceholder(i), section, 1)
+	}
+	return result, nil
+}
+
+// sectionPlaceholder returns the marker that stands in for a rendered section
+func sectionPlaceholder(index int) string {
+	return "\x00rows-" + strconv.Itoa(index) + "\x00"
 }
 
 // Render processes a template with the provided data
@@ -47,13 +106,14 @@ func (r *Renderer) Render(tmplContent string, data map[string]string) (string, e
 	for _, match := range matches {
 		if len(match) > 1 {
 			varName := strings.TrimSpace(match[1])
-			// Replace {{varName}} with {{$.varName}} but only if the variable is in data
-			// This prevents errors when the template contains variables not in the data map
+			// Replace {{varName}} with {{index $ "varName"}} but only if the variable is in data
+			// This prevents errors when the template contains variables not in the data map,
+			// and allows names that are not valid Go template fields (e.g. "rows.labels")
 			if _, exists := data[varName]; exists {
-				tmplContent = strings.ReplaceAll(tmplContent, "{{"+varName+"}}", "{{$."+varName+"}}")
+				tmplContent = strings.ReplaceAll(tmplContent, match[0], "{{index $ "+strconv.Quote(varName)+"}}")
 			} else {
 				// For non-existent variables, replace with empty string
-				tmplContent = strings.ReplaceAll(tmplContent, "{{"+varName+"}}", "")
+				tmplContent = strings.ReplaceAll(tmplContent, match[0], "")
 			}
 		}
 	}
@@ -2,7 +2,6 @@ package template
 
 import (
 	"reflect"
-	"sort"
 	"strings"
 	"testing"
 )
@@ -33,6 +32,11 @@ func TestExtractVariables(t *testing.T) {
 			template: "{{ name }}, {{ greeting }}!",
 			expected: []string{"name", "greeting"},
 		},
+		{
+			name:     "Section tags are not variables",
+			template: "{{#rows}}- {{title}}\n{{/rows}}{{rows.labels}}",
+			expected: []string{"title", "rows.labels"},
+		},
 		{
 			name: "Markdown template with frontmatter",
 			template: `---
@@ -52,10 +56,6 @@ labels: {{label1}}, {{label2}}
 			renderer := NewRenderer()
 			result := renderer.ExtractVariables(tc.template)
 
-			// Sort both slices for comparison
-			sort.Strings(result)
-			sort.Strings(tc.expected)
-
 			if !reflect.DeepEqual(result, tc.expected) {
 				t.Errorf("Expected variables %v, got %v", tc.expected, result)
 			}
@@ -89,6 +89,18 @@ func TestRender(t *testing.T) {
 			data:     map[string]string{"name": "World"},
 			expected: "Hello, World! Today is .",
 		},
+		{
+			name:     "Variables with whitespace",
+			template: "Hello, {{ name }}!",
+			data:     map[string]string{"name": "World"},
+			expected: "Hello, World!",
+		},
+		{
+			name:     "Variable names with dots and dashes",
+			template: "{{rows.labels}} / {{due-date}}",
+			data:     map[string]string{"rows.labels": "bug, ui", "due-date": "2024-01-31"},
+			expected: "bug, ui / 2024-01-31",
+		},
 		{
 			name:     "Multiline template",
 			template: "Title: {{title}}\nDescription: {{description}}",
@@ -129,3 +141,28 @@ func TestRenderWithInvalidTemplate(t *testing.T) {
 		t.Errorf("Expected error to contain template error information, got '%s'", err.Error())
 	}
 }
+
+func TestRenderGroup(t *testing.T) {
+	template := `# {{component}} ({{rows.count}})
+{{#rows}}- [ ] {{title}} ({{severity}})
+{{/rows}}Labels: {{rows.labels}}`
+	data := map[string]string{"component": "payments", "rows.count": "2", "rows.labels": "bug, perf"}
+	rows := []map[string]string{
+		{"title": "Refund fails", "severity": "high"},
+		{"title": "Slow {{checkout}}", "severity": "low"},
+	}
+
+	renderer := NewRenderer()
+	result, err := renderer.RenderGroup(template, data, rows)
+	if err != nil {
+		t.Fatalf("RenderGroup failed: %v", err)
+	}
+
+	expected := `# payments (2)
+- [ ] Refund fails (high)
+- [ ] Slow {{checkout}} (low)
+Labels: bug, perf`
+	if result != expected {
+		t.Errorf("Expected '%s', got '%s'", expected, result)
+	}
+}
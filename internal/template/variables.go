@@ -0,0 +1,91 @@
+package template
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VariableDoc documents a template variable for people filling in the CSV
+type VariableDoc struct {
+	Description string `yaml:"description"`
+	Example     string `yaml:"example"`
+}
+
+// ParseVariableDocs extracts the variables block from the front matter of a
+// rendered template. Each variable maps to a description string or to a
+// mapping with description and example. It returns nil if there is no block.
+func (p *Parser) ParseVariableDocs(content string) (map[string]VariableDoc, error) {
+	if !strings.HasPrefix(content, "---") {
+		return nil, nil
+	}
+
+	parts := strings.SplitN(content, "---", 3)
+	if len(parts) < 3 {
+		return nil, nil
+	}
+
+	var frontMatter struct {
+		Variables yaml.Node `yaml:"variables"`
+	}
+	if err := yaml.Unmarshal([]byte(parts[1]), &frontMatter); err != nil {
+		return nil, fmt.Errorf("failed to parse front matter: %v", err)
+	}
+
+	node := &frontMatter.Variables
+	if node.Kind == 0 {
+		return nil, nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("variables must be a mapping of names to descriptions")
+	}
+
+	docs := make(map[string]VariableDoc)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name := node.Content[i].Value
+		value := node.Content[i+1]
+
+		var doc VariableDoc
+		switch value.Kind {
+		case yaml.ScalarNode:
+			doc.Description = value.Value
+		case yaml.MappingNode:
+			if err := value.Decode(&doc); err != nil {
+				return nil, fmt.Errorf("invalid documentation for variable '%s': %v", name, err)
+			}
+		default:
+			return nil, fmt.Errorf("variable '%s' must be documented with a string or a mapping", name)
+		}
+		docs[name] = doc
+	}
+
+	return docs, nil
+}
+
+// FieldVariables maps each top-level front matter field of a template, and
+// "body" for the rest of it, to the variables used to fill it, in the order
+// they first appear
+func (r *Renderer) FieldVariables(tmplContent string) map[string][]string {
+	fields := make(map[string][]string)
+	add := func(field string, text string) {
+		for _, name := range r.ExtractVariables(text) {
+			if !slices.Contains(fields[field], name) {
+				fields[field] = append(fields[field], name)
+			}
+		}
+	}
+
+	frontMatter, body, ok := splitTemplate(tmplContent)
+	if ok {
+		for _, field := range splitFrontMatterFields(frontMatter) {
+			if field.key != "" {
+				add(field.key, field.text)
+			}
+		}
+	}
+	add("body", body)
+
+	return fields
+}
@@ -0,0 +1,92 @@
+package template
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVariableDocs(t *testing.T) {
+	testCases := []struct {
+		name          string
+		content       string
+		expected      map[string]VariableDoc
+		expectedError bool
+	}{
+		{
+			name:     "No variables",
+			content:  "---\ntitle: Test\n---\nBody",
+			expected: nil,
+		},
+		{
+			name: "Descriptions and examples",
+			content: `---
+title: "{{title}}"
+variables:
+  title: Short summary of the task
+  due:
+    description: Due date
+    example: 2026-12-01
+---
+Body`,
+			expected: map[string]VariableDoc{
+				"title": {Description: "Short summary of the task"},
+				"due":   {Description: "Due date", Example: "2026-12-01"},
+			},
+		},
+		{
+			name:          "Variables is not a mapping",
+			content:       "---\nvariables: [title]\n---\nBody",
+			expectedError: true,
+		},
+		{
+			name:          "Variable documented with a list",
+			content:       "---\nvariables:\n  title: [a, b]\n---\nBody",
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			parser := NewParser()
+			docs, err := parser.ParseVariableDocs(tc.content)
+
+			if tc.expectedError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+
+			if !reflect.DeepEqual(docs, tc.expected) {
+				t.Errorf("Expected docs %+v, got %+v", tc.expected, docs)
+			}
+		})
+	}
+}
+
+func TestFieldVariables(t *testing.T) {
+	content := `---
+title: "[{{area}}] {{summary}}"
+labels:
+  - {{label}}
+  - {{area}}
+assignees: {{owner}}
+---
+{{details}}
+{{#rows}}- {{task}}{{/rows}}`
+
+	expected := map[string][]string{
+		"title":     {"area", "summary"},
+		"labels":    {"label", "area"},
+		"assignees": {"owner"},
+		"body":      {"details", "task"},
+	}
+
+	fields := NewRenderer().FieldVariables(content)
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("Expected fields %v, got %v", expected, fields)
+	}
+}
@@ -0,0 +1,130 @@
+// Package undo closes or deletes the issues a recorded run created. Every
+// issue is checked before any is changed, so that issues someone else has
+// edited or commented on since the run are never undone by accident.
+package undo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ntsk/gh-issue-bulk-create/internal/runlog"
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+// Client is what undoing a run needs from GitHub
+type Client interface {
+	GetRepository(repo string) (*models.Repository, error)
+	GetIssueActivity(repo string, nodeID string) (*models.IssueActivity, error)
+	CreateComment(repo string, number int, body string) (*models.CommentResponse, error)
+	CloseIssue(repo string, number int, stateReason string) error
+	DeleteIssue(repo string, nodeID string) error
+}
+
+// Options controls how a run is undone
+type Options struct {
+	// Delete deletes the issues instead of closing them as not planned
+	Delete bool
+	// SkipModified leaves modified issues and undoes the rest instead of refusing
+	SkipModified bool
+	// Comment is posted before closing; a default naming the run is used if empty
+	Comment string
+}
+
+// Skip is an issue left alone because it is already undone
+type Skip struct {
+	Issue  runlog.Issue
+	Reason string
+}
+
+// Modification is an issue changed by someone else since the run
+type Modification struct {
+	Issue  runlog.Issue
+	Actors []string
+}
+
+// Plan is what undoing a run would do
+type Plan struct {
+	Targets  []runlog.Issue
+	Skipped  []Skip
+	Modified []Modification
+}
+
+// Refused reports whether the run must not be undone: some issues were
+// modified and they are not to be skipped
+func (p *Plan) Refused(opts Options) bool {
+	return len(p.Modified) > 0 && !opts.SkipModified
+}
+
+// Result is the outcome of undoing a single issue
+type Result struct {
+	Issue runlog.Issue
+	Err   error
+}
+
+// Check looks at every issue of the run without changing anything. Deleting
+// requires admin access to every repository, and every repository lacking it
+// is reported in the error.
+func Check(client Client, run *runlog.Run, opts Options) (*Plan, error) {
+	if opts.Delete {
+		var problems []string
+		checked := make(map[string]bool)
+		for _, issue := range run.Issues {
+			if checked[issue.Repo] {
+				continue
+			}
+			checked[issue.Repo] = true
+			repository, err := client.GetRepository(issue.Repo)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("repository %s: %v", issue.Repo, err))
+			} else if !repository.Permissions.Admin {
+				problems = append(problems, fmt.Sprintf("deleting issues in %s requires admin access, you have %s access", issue.Repo, repository.Permissions.Level()))
+			}
+		}
+		if len(problems) > 0 {
+			return nil, errors.New(strings.Join(problems, "; "))
+		}
+	}
+
+	plan := &Plan{}
+	for _, issue := range run.Issues {
+		activity, err := client.GetIssueActivity(issue.Repo, issue.NodeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s#%d: %v", issue.Repo, issue.Number, err)
+		}
+		switch {
+		case activity == nil:
+			plan.Skipped = append(plan.Skipped, Skip{Issue: issue, Reason: "already deleted"})
+		case len(activity.OtherActors()) > 0:
+			plan.Modified = append(plan.Modified, Modification{Issue: issue, Actors: activity.OtherActors()})
+		// Issues the run created closed are closed again as not planned
+		case !opts.Delete && activity.State == "closed" && issue.State != "closed":
+			plan.Skipped = append(plan.Skipped, Skip{Issue: issue, Reason: "already closed"})
+		default:
+			plan.Targets = append(plan.Targets, issue)
+		}
+	}
+	return plan, nil
+}
+
+// Execute closes or deletes the targets of the plan in order. Closing posts
+// the comment first and closes the issue as not planned, which also marks
+// issues the run created closed as undone.
+func Execute(client Client, run *runlog.Run, plan *Plan, opts Options) []Result {
+	comment := opts.Comment
+	if comment == "" {
+		comment = fmt.Sprintf("Closing as not planned: undoing bulk run %s.", run.ID)
+	}
+
+	results := make([]Result, len(plan.Targets))
+	for i, issue := range plan.Targets {
+		var err error
+		if opts.Delete {
+			err = client.DeleteIssue(issue.Repo, issue.NodeID)
+		} else if _, err = client.CreateComment(issue.Repo, issue.Number, comment); err == nil {
+			err = client.CloseIssue(issue.Repo, issue.Number, "not_planned")
+		}
+		results[i] = Result{Issue: issue, Err: err}
+	}
+	return results
+}
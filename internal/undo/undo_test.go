@@ -0,0 +1,207 @@
+package undo
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ntsk/gh-issue-bulk-create/internal/github"
+	"github.com/ntsk/gh-issue-bulk-create/internal/github/githubtest"
+	"github.com/ntsk/gh-issue-bulk-create/internal/runlog"
+)
+
+// newFakeClient creates a GitHub client talking to a fake GitHub server
+func newFakeClient(t *testing.T, handler http.Handler) *github.Client {
+	t.Helper()
+	return github.WithClients(githubtest.Clients(t, handler))
+}
+
+// issue returns an open or closed issue created by "bot", commented on by
+// commenters
+func issue(number int, state string, commenters ...string) *githubtest.Issue {
+	return &githubtest.Issue{Number: number, State: state, Author: "bot", Commenters: commenters}
+}
+
+// newRun returns a run that created issues #1 to #4 in octo/api
+func newRun() *runlog.Run {
+	run := runlog.NewRun("github.com", time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC))
+	for i := 1; i <= 4; i++ {
+		run.Add(runlog.Issue{Repo: "octo/api", Number: i, NodeID: fmt.Sprintf("I_%d", i), Title: fmt.Sprintf("Issue %d", i)})
+	}
+	return run
+}
+
+func TestCheck(t *testing.T) {
+	fake := &githubtest.Issues{Nodes: map[string]*githubtest.Issue{
+		"I_1": issue(1, "OPEN"),
+		"I_2": issue(2, "OPEN", "alice"),
+		"I_3": issue(3, "CLOSED"),
+	}}
+	client := newFakeClient(t, fake)
+	run := newRun()
+
+	plan, err := Check(client, run, Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(plan.Targets) != 1 || plan.Targets[0].Number != 1 {
+		t.Errorf("Expected only #1 to be undone, got %v", plan.Targets)
+	}
+	expectedSkips := []Skip{{Issue: run.Issues[2], Reason: "already closed"}, {Issue: run.Issues[3], Reason: "already deleted"}}
+	if !reflect.DeepEqual(plan.Skipped, expectedSkips) {
+		t.Errorf("Expected skips %v, got %v", expectedSkips, plan.Skipped)
+	}
+	expectedModified := []Modification{{Issue: run.Issues[1], Actors: []string{"alice"}}}
+	if !reflect.DeepEqual(plan.Modified, expectedModified) {
+		t.Errorf("Expected modified issues %v, got %v", expectedModified, plan.Modified)
+	}
+
+	// Modified issues refuse the undo unless they are skipped
+	if !plan.Refused(Options{}) {
+		t.Error("Expected the undo to be refused")
+	}
+	if plan.Refused(Options{SkipModified: true}) {
+		t.Error("Expected the undo not to be refused with SkipModified")
+	}
+
+	// Checking, as done for a dry run, changes nothing
+	if len(fake.Changes) != 0 {
+		t.Errorf("Expected no changes, got %v", fake.Changes)
+	}
+}
+
+func TestExecuteSkipModified(t *testing.T) {
+	fake := &githubtest.Issues{Nodes: map[string]*githubtest.Issue{
+		"I_1": issue(1, "OPEN"),
+		"I_2": issue(2, "OPEN", "alice"),
+		"I_3": issue(3, "OPEN", "bot"),
+		"I_4": issue(4, "OPEN"),
+	}}
+	client := newFakeClient(t, fake)
+	run := newRun()
+	opts := Options{SkipModified: true}
+
+	plan, err := Check(client, run, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for _, result := range Execute(client, run, plan, opts) {
+		if result.Err != nil {
+			t.Errorf("Expected #%d to be closed, got: %v", result.Issue.Number, result.Err)
+		}
+	}
+
+	// The issue commented on by alice is left open; the others get a comment and are closed
+	comment := `{"body":"Closing as not planned: undoing bulk run 20261018-093000."}`
+	closed := `{"state":"closed","state_reason":"not_planned"}`
+	expected := []string{
+		"POST /api/v3/repos/octo/api/issues/1/comments " + comment,
+		"PATCH /api/v3/repos/octo/api/issues/1 " + closed,
+		"POST /api/v3/repos/octo/api/issues/3/comments " + comment,
+		"PATCH /api/v3/repos/octo/api/issues/3 " + closed,
+		"POST /api/v3/repos/octo/api/issues/4/comments " + comment,
+		"PATCH /api/v3/repos/octo/api/issues/4 " + closed,
+	}
+	if !reflect.DeepEqual(fake.Changes, expected) {
+		t.Errorf("Expected changes:\n%s\nGot:\n%s", strings.Join(expected, "\n"), strings.Join(fake.Changes, "\n"))
+	}
+}
+
+func TestExecuteCreatedClosed(t *testing.T) {
+	fake := &githubtest.Issues{Nodes: map[string]*githubtest.Issue{
+		"I_1": issue(1, "CLOSED"),
+		"I_2": issue(2, "CLOSED"),
+	}}
+	client := newFakeClient(t, fake)
+	run := newRun()
+	run.Issues = run.Issues[:2]
+	run.Issues[0].State = "closed"
+
+	plan, err := Check(client, run, Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	expectedSkips := []Skip{{Issue: run.Issues[1], Reason: "already closed"}}
+	if !reflect.DeepEqual(plan.Skipped, expectedSkips) {
+		t.Errorf("Expected skips %v, got %v", expectedSkips, plan.Skipped)
+	}
+	Execute(client, run, plan, Options{})
+
+	// The issue created closed is closed again as not planned; the one closed
+	// since the run is left alone
+	expected := []string{
+		`POST /api/v3/repos/octo/api/issues/1/comments {"body":"Closing as not planned: undoing bulk run 20261018-093000."}`,
+		`PATCH /api/v3/repos/octo/api/issues/1 {"state":"closed","state_reason":"not_planned"}`,
+	}
+	if !reflect.DeepEqual(fake.Changes, expected) {
+		t.Errorf("Expected changes:\n%s\nGot:\n%s", strings.Join(expected, "\n"), strings.Join(fake.Changes, "\n"))
+	}
+}
+
+func TestDelete(t *testing.T) {
+	nodes := map[string]*githubtest.Issue{
+		"I_1": issue(1, "OPEN"),
+		"I_2": issue(2, "CLOSED"),
+	}
+	run := newRun()
+	run.Issues = run.Issues[:3]
+	opts := Options{Delete: true}
+
+	// Deleting requires admin access
+	fake := &githubtest.Issues{Nodes: nodes}
+	_, err := Check(newFakeClient(t, fake), run, opts)
+	if err == nil || !strings.Contains(err.Error(), "deleting issues in octo/api requires admin access, you have write access") {
+		t.Errorf("Expected admin access error, got: %v", err)
+	}
+
+	fake = &githubtest.Issues{Admin: true, Nodes: nodes}
+	client := newFakeClient(t, fake)
+	plan, err := Check(client, run, opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for _, result := range Execute(client, run, plan, opts) {
+		if result.Err != nil {
+			t.Errorf("Expected #%d to be deleted, got: %v", result.Issue.Number, result.Err)
+		}
+	}
+
+	// Closed issues are deleted too; deleted ones are skipped
+	if expected := []string{"delete I_1", "delete I_2"}; !reflect.DeepEqual(fake.Changes, expected) {
+		t.Errorf("Expected changes %v, got %v", expected, fake.Changes)
+	}
+	if len(plan.Skipped) != 1 || plan.Skipped[0].Reason != "already deleted" {
+		t.Errorf("Expected #3 to be skipped as deleted, got %v", plan.Skipped)
+	}
+}
+
+func TestExecuteHostRepo(t *testing.T) {
+	fake := &githubtest.Issues{Nodes: map[string]*githubtest.Issue{"I_1": issue(1, "OPEN")}}
+	client := newFakeClient(t, fake)
+
+	// Runs against another host record repositories in HOST/OWNER/REPO form
+	run := runlog.NewRun(client.Host(), time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC))
+	run.Add(runlog.Issue{Repo: client.Host() + "/octo/api", Number: 1, NodeID: "I_1", Title: "Issue 1"})
+
+	plan, err := Check(client, run, Options{Comment: "Undo"})
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+	for _, result := range Execute(client, run, plan, Options{Comment: "Undo"}) {
+		if result.Err != nil {
+			t.Errorf("Expected #%d to be closed, got: %v", result.Issue.Number, result.Err)
+		}
+	}
+
+	expected := []string{
+		`POST /api/v3/repos/octo/api/issues/1/comments {"body":"Undo"}`,
+		`PATCH /api/v3/repos/octo/api/issues/1 {"state":"closed","state_reason":"not_planned"}`,
+	}
+	if !reflect.DeepEqual(fake.Changes, expected) {
+		t.Errorf("Expected changes:\n%s\nGot:\n%s", strings.Join(expected, "\n"), strings.Join(fake.Changes, "\n"))
+	}
+}
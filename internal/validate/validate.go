@@ -0,0 +1,123 @@
+// Package validate provides offline checks of rendered issues against the limits
+// GitHub enforces, so that problems are found before any request is made.
+package validate
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+// Limits enforced by GitHub when creating issues
+const (
+	MaxTitleLength = 256
+	MaxBodyLength  = 65536
+	MaxAssignees   = 10
+)
+
+// Item is a rendered issue along with a description of where it came from.
+// Columns maps each front matter field (and "body") to the CSV columns the
+// item's template fills it from, for problem references.
+type Item struct {
+	Source  string
+	Issue   *models.Issue
+	Columns map[string][]string
+}
+
+// Problem is a single validation failure. Field is the issue field at fault
+// and Columns the CSV columns the template fills it from, if known.
+type Problem struct {
+	Source  string
+	Field   string
+	Columns []string
+	Message string
+}
+
+// String formats the problem for display
+func (p Problem) String() string {
+	location := p.Source
+	if p.Field != "" {
+		location += ", " + p.Field
+		if len(p.Columns) > 0 {
+			location += fmt.Sprintf(" (column %s)", strings.Join(p.Columns, ", "))
+		}
+	}
+	if location == "" {
+		return p.Message
+	}
+	return fmt.Sprintf("%s: %s", location, p.Message)
+}
+
+// Validator checks rendered issues without contacting GitHub
+type Validator struct {
+	defaultRepo string
+}
+
+// NewValidator creates a new validator. defaultRepo is the repository of
+// issues without a repo front matter value, as given with --repo, or empty
+// for the current repository.
+func NewValidator(defaultRepo string) *Validator {
+	return &Validator{defaultRepo: defaultRepo}
+}
+
+// Check validates every item and returns all problems found
+func (v *Validator) Check(items []Item) []Problem {
+	var problems []Problem
+	report := func(item Item, field string, format string, args ...interface{}) {
+		problems = append(problems, Problem{
+			Source:  item.Source,
+			Field:   field,
+			Columns: item.Columns[field],
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	// Titles only need to be unique within a repository
+	titles := make(map[string]string)
+	for _, item := range items {
+		issue := item.Issue
+
+		title := strings.TrimSpace(issue.Title)
+		switch {
+		case title == "":
+			report(item, "title", "title is empty")
+		case utf8.RuneCountInString(issue.Title) > MaxTitleLength:
+			report(item, "title", "title is %d characters long (max %d)", utf8.RuneCountInString(issue.Title), MaxTitleLength)
+		}
+		if title != "" {
+			repo := issue.Repo
+			if repo == "" {
+				repo = v.defaultRepo
+			}
+			key := strings.ToLower(repo) + "\x00" + title
+			if first, ok := titles[key]; ok {
+				report(item, "title", "duplicate title '%s' (also %s)", title, first)
+			} else {
+				titles[key] = item.Source
+			}
+		}
+
+		if length := utf8.RuneCountInString(issue.Body); length > MaxBodyLength {
+			report(item, "body", "body is %d characters long (max %d)", length, MaxBodyLength)
+		}
+		for i, comment := range issue.Comments {
+			if length := utf8.RuneCountInString(comment); length > MaxBodyLength {
+				report(item, "comments", "comment %d is %d characters long (max %d)", i+1, length, MaxBodyLength)
+			}
+		}
+
+		if len(issue.Assignees) > MaxAssignees {
+			report(item, "assignees", "%d assignees (max %d)", len(issue.Assignees), MaxAssignees)
+		}
+
+		if issue.Repo != "" {
+			if _, err := models.ParseRepoRef(issue.Repo); err != nil {
+				report(item, "repo", "%v", err)
+			}
+		}
+	}
+
+	return problems
+}
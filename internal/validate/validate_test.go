@@ -0,0 +1,104 @@
+package validate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+func TestCheck(t *testing.T) {
+	columns := map[string][]string{"title": {"summary", "area"}, "body": {"details"}, "comments": {"notes"}}
+
+	manyAssignees := make([]string, MaxAssignees+1)
+	for i := range manyAssignees {
+		manyAssignees[i] = "user"
+	}
+
+	testCases := []struct {
+		name     string
+		items    []Item
+		expected []string
+	}{
+		{
+			name: "Valid issues",
+			items: []Item{
+				{Source: "row 1", Issue: &models.Issue{Title: "First", Body: "Body"}},
+				{Source: "row 2", Issue: &models.Issue{Title: "Second", Assignees: []string{"alice"}}},
+			},
+		},
+		{
+			name: "Empty and long titles",
+			items: []Item{
+				{Source: "row 1", Issue: &models.Issue{Title: "  "}},
+				{Source: "row 2", Issue: &models.Issue{Title: strings.Repeat("é", MaxTitleLength+1)}},
+				{Source: "row 3", Issue: &models.Issue{Title: strings.Repeat("é", MaxTitleLength)}},
+			},
+			expected: []string{
+				"row 1, title (column summary, area): title is empty",
+				"row 2, title (column summary, area): title is 257 characters long (max 256)",
+			},
+		},
+		{
+			name: "Duplicate titles in the same repository",
+			items: []Item{
+				{Source: "row 1", Issue: &models.Issue{Title: "Upgrade"}},
+				{Source: "row 2", Issue: &models.Issue{Title: "Upgrade", Repo: "octo/web"}},
+				{Source: "row 3", Issue: &models.Issue{Title: "Upgrade "}},
+			},
+			expected: []string{
+				"row 3, title (column summary, area): duplicate title 'Upgrade' (also row 1)",
+			},
+		},
+		{
+			name: "Duplicate titles in the default repository",
+			items: []Item{
+				{Source: "row 1", Issue: &models.Issue{Title: "Upgrade"}},
+				{Source: "row 2", Issue: &models.Issue{Title: "Upgrade", Repo: "Octo/API"}},
+				{Source: "row 3", Issue: &models.Issue{Title: "Upgrade", Repo: "octo/web"}},
+			},
+			expected: []string{
+				"row 2, title (column summary, area): duplicate title 'Upgrade' (also row 1)",
+			},
+		},
+		{
+			name: "Body, comments, assignees and repository",
+			items: []Item{
+				{Source: "row 1", Issue: &models.Issue{
+					Title:     "Big",
+					Body:      strings.Repeat("a", MaxBodyLength+1),
+					Comments:  []string{"ok", strings.Repeat("a", MaxBodyLength+1)},
+					Assignees: manyAssignees,
+					Repo:      "octo",
+				}},
+			},
+			expected: []string{
+				"row 1, body (column details): body is 65537 characters long (max 65536)",
+				"row 1, comments (column notes): comment 2 is 65537 characters long (max 65536)",
+				"row 1, assignees: 11 assignees (max 10)",
+				"row 1, repo: 'octo' is not in OWNER/REPO or HOST/OWNER/REPO format",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var result []string
+			for _, problem := range NewValidator("octo/api").Check(withColumns(tc.items, columns)) {
+				result = append(result, problem.String())
+			}
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("Expected problems %q, got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+// withColumns sets the same column references on every item
+func withColumns(items []Item, columns map[string][]string) []Item {
+	for i := range items {
+		items[i].Columns = columns
+	}
+	return items
+}
@@ -1,62 +1,271 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
+	"github.com/ntsk/gh-issue-bulk-create/internal/assignee"
+	"github.com/ntsk/gh-issue-bulk-create/internal/auth"
 	"github.com/ntsk/gh-issue-bulk-create/internal/csv"
+	"github.com/ntsk/gh-issue-bulk-create/internal/filter"
 	"github.com/ntsk/gh-issue-bulk-create/internal/github"
+	"github.com/ntsk/gh-issue-bulk-create/internal/input"
+	"github.com/ntsk/gh-issue-bulk-create/internal/mapping"
+	"github.com/ntsk/gh-issue-bulk-create/internal/plan"
+	"github.com/ntsk/gh-issue-bulk-create/internal/preflight"
+	"github.com/ntsk/gh-issue-bulk-create/internal/runlog"
 	"github.com/ntsk/gh-issue-bulk-create/internal/template"
+	"github.com/ntsk/gh-issue-bulk-create/internal/undo"
+	"github.com/ntsk/gh-issue-bulk-create/internal/validate"
+	"github.com/ntsk/gh-issue-bulk-create/pkg/models"
+)
+
+// Supported values for --target
+const (
+	targetIssue      = "issue"
+	targetDiscussion = "discussion"
+	targetProject    = "project"
+)
+
+// defaultPlanFile is the file plan writes when --out is not given
+const defaultPlanFile = "issues.plan.json"
+
+// Supported values for --api
+const (
+	apiREST    = "rest"
+	apiGraphQL = "graphql"
 )
 
 // CommandLineOptions holds the command line options
 type CommandLineOptions struct {
 	templateFile string
+	templateDir  string
 	csvFile      string
 	dryRun       bool
 	repo         string
+	matrix       matrixFlag
+	groupBy      string
+	listColumns  string
+	target       string
+	project      string
+	hostname     string
+	api          string
+	batchSize    int
+	tokenEnv     string
+	appID        int64
+	appInstall   int64
+	appKeyFile   string
+	planFile     string
+	stateDir     string
+	undoDelete   bool
+	undoComment  string
+	skipModified bool
+	search       string
+	state        string
+	labels       string
+	milestone    string
+	assignee     string
+	columns      string
+	output       string
+	example      bool
+	rows         string
+	where        string
+	limit        int
+	skipExisting bool
+	mapping      string
 	showHelp     bool
+	args         []string
+}
+
+// matrixFlag collects repeated --matrix NAME=VALUE1,VALUE2 options
+type matrixFlag []template.MatrixAxis
+
+func (m *matrixFlag) String() string {
+	var parts []string
+	for _, axis := range *m {
+		parts = append(parts, axis.Name+"="+strings.Join(axis.Values, ","))
+	}
+	return strings.Join(parts, " ")
+}
+
+func (m *matrixFlag) Set(value string) error {
+	axis, err := template.ParseMatrixFlag(value)
+	if err != nil {
+		return err
+	}
+	*m = append(*m, axis)
+	return nil
+}
+
+// toMatrix converts the collected axes into a matrix, or nil if none were given
+func (m matrixFlag) toMatrix() *template.Matrix {
+	if len(m) == 0 {
+		return nil
+	}
+	return &template.Matrix{Axes: m}
 }
 
 func printHelp() {
-	helpText := `Usage: gh issue-bulk-create [options]
+	helpText := `Usage: gh issue-bulk-create [command] [options]
 
 Create multiple GitHub issues in bulk using a template file and CSV data.
 
+Commands:
+  (none)                Create issues from the template and CSV data
+  plan                  Check and render every issue into a plan file for review
+  apply PLAN            Create exactly the issues in a plan file
+  undo RUN              Close (or with --delete, delete) the issues a run created
+  export                Write existing issues of --repo to CSV
+  init                  Write a starter CSV with the variables of --template
+  validate              Check the template and CSV offline, without GitHub access
+  templates list        List the issue templates of the repository
+
 Options:
-  --template FILE       Path to the template markdown file (required)
+  --template FILE       Path to the template markdown file or issue form, or
+                        the name of a template in .github/ISSUE_TEMPLATE of
+                        the local checkout or --repo (required)
+  --template-dir DIR    Pick each row's template from DIR by the "template"
+                        column. --template is used for rows without one
   --csv FILE            Path to the CSV file containing data (required)
-  --repo OWNER/REPO     Target repository (default: current repository)
-  --dry-run             Only show the content of issues without creating them
+  --mapping FILE        YAML file renaming, defaulting and deriving CSV columns
+                        before rendering, for CSV exported by other tools
+  --repo [HOST/]OWNER/REPO
+                        Target repository (default: current repository).
+                        A "repo" front matter value overrides it per row
+  --hostname HOST       GitHub host to use, e.g. a GitHub Enterprise Server
+                        (default: GH_HOST or github.com). Repositories given
+                        as HOST/OWNER/REPO use their own host
+  --api TYPE            API used to create issues: "rest" (default) or "graphql".
+                        GraphQL creates several issues per request; missing
+                        labels are created just before the first batch using
+                        them, and a request that fails as a whole stops the run
+  --batch-size N        Issues created per GraphQL request (default: 25)
+  --token-env NAME      Authenticate with the token in environment variable NAME
+                        instead of gh's login
+  --app-id ID           Authenticate as a GitHub App installation (requires
+                        --app-private-key). Tokens are refreshed during long runs
+  --app-installation-id ID
+                        Installation to use (default: the app's only installation)
+  --app-private-key FILE
+                        Path to the GitHub App private key (PEM)
+  --matrix NAME=V1,V2   Expand every row into one issue per value (repeatable).
+                        Combined with any "matrix" front matter block
+  --group-by COLUMN     Create one issue per distinct value of COLUMN. Rows of
+                        a group are repeated with {{#rows}}...{{/rows}}, and
+                        {{rows.COLUMN}} joins the distinct values of a column
+  --list-columns C1,C2  Columns whose cells are comma-separated lists, joined
+                        item by item in {{rows.COLUMN}} (in addition to label,
+                        labels, assignee and assignees)
+  --target TYPE         What to create: "issue" (default), "discussion" or
+                        "project". Discussions use the "category" front matter
+                        value; project drafts use the "fields" front matter map
+  --project OWNER/NUM   Project (v2) to add draft issues to (--target project)
+  --rows 10-20,35       Only use these CSV rows (numbered from 1, after the
+                        header). "N-" runs to the last row
+  --where EXPR          Only use rows matching EXPR, e.g. 'priority == "P1"'
+                        or 'status != "done" && estimate > 3'
+  --limit N             Only use the first N rows left by --rows and --where
+  --skip-existing       Skip rows whose "url" column links to an issue of the
+                        repository they would create an issue in, so that
+                        re-importing an export creates nothing
+  --dry-run             Only show the content of issues without creating them.
+                        Lists the rows left out by --rows, --where and --limit
+  --out FILE            Plan file written by plan (default: issues.plan.json)
+  --state-dir DIR       Where runs are recorded for undo
+                        (default: .gh-issue-bulk-create/runs)
+  --delete              undo: delete the issues instead of closing them
+                        (requires admin access)
+  --comment TEXT        undo: comment posted before closing each issue
+  --skip-modified       undo: leave issues changed by others and undo the rest
+  --search QUERY        export: search query to select issues
+  --state STATE         export: "open", "closed" or "all" (default)
+  --label L1,L2         export: only issues with all of these labels
+  --milestone TITLE     export: only issues in this milestone
+  --assignee USER       export: only issues assigned to this user
+  --columns C1,C2       export: columns to write (default: number, title, body,
+                        labels, assignees, milestone, milestone_number, url,
+                        state, created_at). milestone is the title; use
+                        milestone_number in the front matter
+  --output FILE         export, init: CSV file to write (default: standard output)
+  --example             init: add a row with the examples from the template's
+                        "variables" front matter
   -h, --help            Show this help message
 
 Examples:
   gh issue-bulk-create --template sample-template.md --csv sample-data.csv
   gh issue-bulk-create --template sample-template.md --csv sample-data.csv --repo owner/repo
   gh issue-bulk-create --template sample-template.md --csv sample-data.csv --dry-run
+  gh issue-bulk-create --template sample-template.md --csv sample-data.csv --hostname ghe.example.com
+  gh issue-bulk-create --template task.md --csv tasks.csv --matrix env=staging,production
+  gh issue-bulk-create --template task.md --csv tasks.csv --where 'status != "done"' --limit 10 --dry-run
+  gh issue-bulk-create plan --template sample-template.md --csv sample-data.csv --out release.plan.json
+  gh issue-bulk-create apply release.plan.json
+  gh issue-bulk-create undo 20261018-093000 --dry-run
+  gh issue-bulk-create export --repo owner/repo --state open --label bug --output bugs.csv
+  gh issue-bulk-create validate --template sample-template.md --csv sample-data.csv
+  gh issue-bulk-create --template-dir templates/ --template chore --csv triage.csv
+  gh issue-bulk-create --template sample-template.md --csv jira-export.csv --mapping jira.yml
+  gh issue-bulk-create templates list --repo owner/repo
+  gh issue-bulk-create --template bug_report --csv bugs.csv --repo owner/repo
+  gh issue-bulk-create init --template sample-template.md --example --output sample-data.csv
 `
 	fmt.Println(helpText)
 }
 
-func parseFlags() CommandLineOptions {
+func parseFlags(args []string) CommandLineOptions {
 	opts := CommandLineOptions{}
 
 	fs := flag.NewFlagSet("gh-issue-bulk-create", flag.ExitOnError)
 
 	fs.StringVar(&opts.templateFile, "template", "", "")
+	fs.StringVar(&opts.templateDir, "template-dir", "", "")
 	fs.StringVar(&opts.csvFile, "csv", "", "")
 	fs.BoolVar(&opts.dryRun, "dry-run", false, "")
 	fs.StringVar(&opts.repo, "repo", "", "")
+	fs.Var(&opts.matrix, "matrix", "")
+	fs.StringVar(&opts.groupBy, "group-by", "", "")
+	fs.StringVar(&opts.listColumns, "list-columns", "", "")
+	fs.StringVar(&opts.target, "target", targetIssue, "")
+	fs.StringVar(&opts.project, "project", "", "")
+	fs.StringVar(&opts.hostname, "hostname", "", "")
+	fs.StringVar(&opts.api, "api", apiREST, "")
+	fs.IntVar(&opts.batchSize, "batch-size", github.DefaultBatchSize, "")
+	fs.StringVar(&opts.tokenEnv, "token-env", "", "")
+	fs.Int64Var(&opts.appID, "app-id", 0, "")
+	fs.Int64Var(&opts.appInstall, "app-installation-id", 0, "")
+	fs.StringVar(&opts.appKeyFile, "app-private-key", "", "")
+	fs.StringVar(&opts.planFile, "out", defaultPlanFile, "")
+	fs.StringVar(&opts.stateDir, "state-dir", runlog.DefaultDir, "")
+	fs.BoolVar(&opts.undoDelete, "delete", false, "")
+	fs.StringVar(&opts.undoComment, "comment", "", "")
+	fs.BoolVar(&opts.skipModified, "skip-modified", false, "")
+	fs.StringVar(&opts.search, "search", "", "")
+	fs.StringVar(&opts.state, "state", "all", "")
+	fs.StringVar(&opts.labels, "label", "", "")
+	fs.StringVar(&opts.milestone, "milestone", "", "")
+	fs.StringVar(&opts.assignee, "assignee", "", "")
+	fs.StringVar(&opts.columns, "columns", "", "")
+	fs.StringVar(&opts.output, "output", "", "")
+	fs.BoolVar(&opts.example, "example", false, "")
+	fs.StringVar(&opts.rows, "rows", "", "")
+	fs.StringVar(&opts.where, "where", "", "")
+	fs.IntVar(&opts.limit, "limit", 0, "")
+	fs.BoolVar(&opts.skipExisting, "skip-existing", false, "")
+	fs.StringVar(&opts.mapping, "mapping", "", "")
 	fs.BoolVar(&opts.showHelp, "help", false, "")
 	fs.BoolVar(&opts.showHelp, "h", false, "")
 
 	fs.Usage = printHelp
 
 	// Check for -h or --help in arguments
-	for _, arg := range os.Args[1:] {
+	for _, arg := range args {
 		if arg == "-h" || arg == "--help" {
 			opts.showHelp = true
 			break
@@ -64,14 +273,41 @@ func parseFlags() CommandLineOptions {
 	}
 
 	// Parse flags
-	fs.Parse(os.Args[1:])
+	fs.Parse(args)
+	opts.args = fs.Args()
 
 	return opts
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "plan":
+			runPlan(os.Args[2:])
+			return
+		case "apply":
+			runApply(os.Args[2:])
+			return
+		case "undo":
+			runUndo(os.Args[2:])
+			return
+		case "export":
+			runExport(os.Args[2:])
+			return
+		case "init":
+			runInit(os.Args[2:])
+			return
+		case "validate":
+			runValidate(os.Args[2:])
+			return
+		case "templates":
+			runTemplates(os.Args[2:])
+			return
+		}
+	}
+
 	// Parse command line arguments
-	opts := parseFlags()
+	opts := parseFlags(os.Args[1:])
 
 	// Show help and exit
 	if opts.showHelp {
@@ -80,157 +316,1578 @@ func main() {
 	}
 
 	// Check required arguments
-	if opts.templateFile == "" || opts.csvFile == "" {
+	if (opts.templateFile == "" && opts.templateDir == "") || opts.csvFile == "" {
 		fmt.Println("Error: Both template file and CSV file must be specified")
 		printHelp()
 		os.Exit(1)
 	}
+	validateOptions(opts)
 
-	// Initialize components
-	csvParser := csv.NewParser()
-	templateRenderer := template.NewRenderer()
-	templateParser := template.NewParser()
+	githubClient := newGitHubClient(opts)
+	issues, repos, project := prepareIssues(opts, githubClient)
+
+	// Check rate limit before creating issues
+	if !opts.dryRun {
+		checkRateLimit(opts, githubClient, repos)
+	}
+
+	createPlannedIssues(opts, githubClient, issues, repos, project)
+}
+
+// runPlan renders and checks every issue like a normal run, then writes them
+// to a plan file instead of creating them
+func runPlan(args []string) {
+	opts := parseFlags(args)
+	if opts.showHelp {
+		printHelp()
+		os.Exit(0)
+	}
+	if (opts.templateFile == "" && opts.templateDir == "") || opts.csvFile == "" {
+		fmt.Println("Error: Both template file and CSV file must be specified")
+		os.Exit(1)
+	}
+	if opts.target != targetIssue {
+		fmt.Printf("Error: plan only supports --target %s\n", targetIssue)
+		os.Exit(1)
+	}
+	validateOptions(opts)
 
-	// Initialize GitHub client
-	githubClient, err := github.NewClient()
+	githubClient := newGitHubClient(opts)
+	issues, repos, _ := prepareIssues(opts, githubClient)
+
+	// Assignees are already resolved, so the plan records the final issue
+	items := make([]plan.Item, len(issues))
+	for i, planned := range issues {
+		issue := *planned.issue
+		issue.Repo = planned.repo
+		issue.CodeownersPath = ""
+		issue.AssigneePool = nil
+		items[i] = plan.NewItem(planned.source, planned.repo, &issue)
+	}
+
+	p, err := plan.New(githubClient.Host(), items, githubClient)
 	if err != nil {
-		fmt.Printf("Failed to initialize GitHub API client: %v\n", err)
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := plan.Write(opts.planFile, p); err != nil {
+		fmt.Printf("Failed to write plan: %v\n", err)
+		os.Exit(1)
+	}
+
+	for _, record := range p.Repositories {
+		if len(record.NewLabels) > 0 {
+			fmt.Printf("Labels that will be created in %s: %s\n", record.Repo, strings.Join(record.NewLabels, ", "))
+		}
+	}
+	fmt.Printf("Plan written to %s: %d issues in %d repositories\n", opts.planFile, len(items), len(repos))
+	fmt.Printf("Review it, then run: gh issue-bulk-create apply %s\n", opts.planFile)
+}
+
+// runApply creates exactly the issues of a plan file, refusing if the plan was
+// edited or the labels and milestones it depends on have changed
+func runApply(args []string) {
+	planFile, opts := parseCommandArgs(args)
+	if planFile == "" {
+		fmt.Println("Error: apply requires a plan file")
+		os.Exit(1)
+	}
+	if opts.templateFile != "" || opts.csvFile != "" || opts.repo != "" || opts.hostname != "" {
+		fmt.Println("Error: apply takes the repositories and issues from the plan; --template, --csv, --repo and --hostname cannot be used")
+		os.Exit(1)
+	}
+	if opts.target != targetIssue {
+		fmt.Printf("Error: apply only supports --target %s\n", targetIssue)
 		os.Exit(1)
 	}
+	validateOptions(opts)
 
-	// Read template file
-	tmplContent, err := os.ReadFile(opts.templateFile)
+	p, err := plan.Read(planFile)
 	if err != nil {
-		fmt.Printf("Failed to read template file: %v\n", err)
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	opts.hostname = p.Host
+	githubClient := newGitHubClient(opts)
+
+	problems, err := p.Check(githubClient)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(problems) > 0 {
+		fmt.Printf("Refusing to apply %s: the repository state it depends on has changed:\n", planFile)
+		for _, problem := range problems {
+			fmt.Printf(" - %s\n", problem)
+		}
+		fmt.Println("Create a new plan to pick up the changes.")
 		os.Exit(1)
 	}
 
-	// Extract variables from template
-	templateVars := templateRenderer.ExtractVariables(string(tmplContent))
+	issues := make([]*plannedIssue, len(p.Items))
+	for i, item := range p.Items {
+		issues[i] = &plannedIssue{source: item.Source, issue: item.Issue, repo: item.Repo}
+	}
+	fmt.Printf("Applying %s: %d issues planned at %s\n", planFile, len(issues), p.CreatedAt.Format(time.RFC3339))
 
-	// Read CSV file
-	records, headers, err := csvParser.Parse(opts.csvFile)
+	// Permissions may have changed since the plan was created
+	if !runPreflight(issues, githubClient) {
+		os.Exit(1)
+	}
+
+	repos := groupByRepo(issues)
+	if !opts.dryRun {
+		checkRateLimit(opts, githubClient, repos)
+	}
+	createPlannedIssues(opts, githubClient, issues, repos, nil)
+}
+
+// parseCommandArgs parses the options of a command taking one argument, which
+// may be given before or after the options
+func parseCommandArgs(args []string) (string, CommandLineOptions) {
+	var arg string
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		arg, args = args[0], args[1:]
+	}
+	opts := parseFlags(args)
+	if opts.showHelp {
+		printHelp()
+		os.Exit(0)
+	}
+	if arg == "" && len(opts.args) > 0 {
+		arg = opts.args[0]
+	}
+	return arg, opts
+}
+
+// runUndo closes or deletes every issue a recorded run created, refusing if
+// any of them has since been edited or commented on by someone else
+func runUndo(args []string) {
+	runID, opts := parseCommandArgs(args)
+	if runID == "" {
+		fmt.Println("Error: undo requires a run ID")
+		os.Exit(1)
+	}
+
+	run, err := runlog.Load(opts.stateDir, runID)
 	if err != nil {
-		// Provide more user-friendly error messages for CSV validation errors
-		if strings.Contains(err.Error(), "CSV file is empty") {
-			fmt.Printf("Error: The CSV file '%s' is empty. Please add headers and data.\n", opts.csvFile)
-		} else if strings.Contains(err.Error(), "empty header") {
-			fmt.Printf("Error: CSV validation failed: %v\n", err)
-			fmt.Println("All columns in the CSV file must have headers. Please check your CSV file.")
-		} else {
-			fmt.Printf("Failed to read CSV file: %v\n", err)
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if run.UndoneAt != nil {
+		fmt.Printf("Error: run %s was already undone at %s\n", run.ID, run.UndoneAt.Format(time.RFC3339))
+		os.Exit(1)
+	}
+	opts.hostname = run.Host
+	githubClient := newGitHubClient(opts)
+
+	// Check every issue before changing any of them
+	undoOpts := undo.Options{Delete: opts.undoDelete, SkipModified: opts.skipModified, Comment: opts.undoComment}
+	plan, err := undo.Check(githubClient, run, undoOpts)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	for _, skip := range plan.Skipped {
+		fmt.Printf("Skipping %s#%d: %s\n", skip.Issue.Repo, skip.Issue.Number, skip.Reason)
+	}
+	if len(plan.Modified) > 0 {
+		fmt.Printf("Issues edited or commented on by someone else since run %s:\n", run.ID)
+		for _, modified := range plan.Modified {
+			fmt.Printf(" - %s#%d was changed by %s\n", modified.Issue.Repo, modified.Issue.Number, strings.Join(modified.Actors, ", "))
+		}
+		if plan.Refused(undoOpts) {
+			fmt.Println("Refusing to undo the run. Use --skip-modified to leave these issues and undo the rest.")
+			os.Exit(1)
+		}
+	}
+
+	action := "Close"
+	if opts.undoDelete {
+		action = "Delete"
+	}
+
+	if opts.dryRun {
+		for _, issue := range plan.Targets {
+			fmt.Printf("Would %s %s#%d: %s\n", strings.ToLower(action), issue.Repo, issue.Number, issue.Title)
+		}
+		fmt.Printf("%d of %d issues would be %sd\n", len(plan.Targets), len(run.Issues), strings.ToLower(action))
+		return
+	}
+
+	failed := 0
+	for _, result := range undo.Execute(githubClient, run, plan, undoOpts) {
+		issue := result.Issue
+		if result.Err != nil {
+			fmt.Printf("Failed to %s %s#%d: %v\n", strings.ToLower(action), issue.Repo, issue.Number, result.Err)
+			failed++
+			continue
 		}
+		fmt.Printf("%sd %s#%d: %s\n", action, issue.Repo, issue.Number, issue.Title)
+	}
+
+	fmt.Printf("%d of %d issues %sd, %d failed\n", len(plan.Targets)-failed, len(run.Issues), strings.ToLower(action), failed)
+	if failed > 0 || len(plan.Modified) > 0 {
 		os.Exit(1)
 	}
 
-	// Validate headers against template variables
-	warnings, err := csvParser.ValidateHeadersAgainstTemplate(headers, templateVars)
+	now := time.Now().UTC()
+	run.UndoneAt = &now
+	if _, err := runlog.Save(opts.stateDir, run); err != nil {
+		fmt.Printf("Warning: failed to record the undo of run %s: %v\n", run.ID, err)
+	}
+}
+
+// runExport writes existing issues matching the filters to CSV, using the
+// same header conventions as the CSV files issues are created from
+func runExport(args []string) {
+	opts := parseFlags(args)
+	if opts.showHelp {
+		printHelp()
+		os.Exit(0)
+	}
+
+	columns, err := csv.ParseColumns(opts.columns)
 	if err != nil {
-		fmt.Printf("Error: Failed to validate CSV headers: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
-	if len(warnings) > 0 {
-		fmt.Println("Validation warnings:")
-		for _, warning := range warnings {
-			fmt.Println(" -", warning)
+	githubClient := newGitHubClient(opts)
+	repo := opts.repo
+	if repo == "" {
+		repo, err = githubClient.GetCurrentRepository()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to determine repository: %v\n", err)
+			fmt.Fprintln(os.Stderr, "Please specify the repository using --repo option, or run in a git repository")
+			os.Exit(1)
 		}
+	}
 
-		if strings.Contains(warnings[0], "missing from CSV headers") {
-			fmt.Println("These missing variables will be left empty in the generated issues.")
-			fmt.Println("Do you want to continue? (y/N)")
-			var response string
-			fmt.Scanln(&response)
-			response = strings.ToLower(strings.TrimSpace(response))
-			if response != "y" && response != "yes" {
-				fmt.Println("Aborted.")
-				os.Exit(0)
+	filter := github.IssueFilter{
+		Repo:      repo,
+		Query:     opts.search,
+		State:     opts.state,
+		Milestone: opts.milestone,
+		Assignee:  opts.assignee,
+	}
+	if opts.labels != "" {
+		for _, label := range strings.Split(opts.labels, ",") {
+			if label = strings.TrimSpace(label); label != "" {
+				filter.Labels = append(filter.Labels, label)
 			}
 		}
 	}
 
-	// Map records to data maps
-	dataMaps := csvParser.MapRecords(records, headers)
+	issues, err := githubClient.SearchIssues(filter)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to search issues in %s: %v\n", repo, err)
+		os.Exit(1)
+	}
 
-	// Determine repository
-	targetRepo := opts.repo
-	if targetRepo == "" {
-		// If not specified as a flag, try to get from current directory
-		targetRepo, err = githubClient.GetCurrentRepository()
+	// Progress goes to standard error so that the CSV can be piped
+	out := os.Stdout
+	if opts.output != "" {
+		file, err := os.Create(opts.output)
 		if err != nil {
-			fmt.Printf("Failed to determine repository: %v\n", err)
-			fmt.Println("Please specify the repository using --repo option or run in a git repository")
+			fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", opts.output, err)
 			os.Exit(1)
 		}
+		defer file.Close()
+		out = file
+	}
+	if err := csv.WriteIssues(out, issues, columns); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write CSV: %v\n", err)
+		os.Exit(1)
 	}
 
-	fmt.Printf("Target repository: %s\n", targetRepo)
+	fmt.Fprintf(os.Stderr, "Exported %d issues from %s\n", len(issues), repo)
+}
 
-	// Check rate limit before creating issues
-	if !opts.dryRun {
-		rateLimit, err := githubClient.GetRateLimit()
+// runInit writes a CSV header row with the template's variables in the order
+// they first appear, optionally followed by a row of documented examples
+func runInit(args []string) {
+	opts := parseFlags(args)
+	if opts.showHelp {
+		printHelp()
+		os.Exit(0)
+	}
+
+	if opts.templateFile == "" {
+		fmt.Fprintln(os.Stderr, "Error: --template is required")
+		os.Exit(1)
+	}
+	if strings.EqualFold(filepath.Ext(opts.output), ".xlsx") {
+		fmt.Fprintln(os.Stderr, "Error: XLSX output is not supported; write a .csv file instead")
+		os.Exit(1)
+	}
+
+	templateRenderer := template.NewRenderer()
+	templateParser := template.NewParser()
+
+	// Matrix variables are not CSV columns, and group variables such as
+	// "rows.labels" read the column they aggregate
+	tmpl := readTemplate(opts, func() *github.Client { return newGitHubClient(opts) }, input.NewLoader(opts.matrix.toMatrix(), templateRenderer, templateParser))
+	templateVars := input.GroupVariableColumns(tmpl.Vars)
+	if len(templateVars) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: %s has no variables\n", opts.templateFile)
+		os.Exit(1)
+	}
+
+	// Issue forms document their fields; Markdown templates may have a
+	// "variables" block, which is literal, so rendering without data is
+	// enough to read it
+	docs := make(map[string]template.VariableDoc)
+	if tmpl.Form != nil {
+		docs = tmpl.Form.VariableDocs()
+	} else {
+		// The first document documenting a variable wins
+		for _, document := range tmpl.Documents {
+			rendered, err := templateRenderer.Render(document.Content, map[string]string{})
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to render template: %v\n", err)
+				os.Exit(1)
+			}
+			documentDocs, err := templateParser.ParseVariableDocs(rendered)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			for name, doc := range documentDocs {
+				if _, ok := docs[name]; !ok {
+					docs[name] = doc
+				}
+			}
+		}
+	}
+
+	var rows [][]string
+	if opts.example {
+		example := make([]string, len(templateVars))
+		for i, name := range templateVars {
+			example[i] = docs[name].Example
+		}
+		rows = append(rows, example)
+	}
+
+	// Descriptions go to standard error so that the CSV can be piped
+	out := os.Stdout
+	if opts.output != "" {
+		file, err := os.Create(opts.output)
 		if err != nil {
-			fmt.Printf("Warning: Failed to check rate limit: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Failed to create %s: %v\n", opts.output, err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := csv.WriteRows(out, templateVars, rows); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write CSV: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(os.Stderr, "Columns of %s:\n", opts.templateFile)
+	for _, name := range templateVars {
+		if doc := docs[name]; doc.Description != "" {
+			fmt.Fprintf(os.Stderr, "  %s: %s\n", name, doc.Description)
 		} else {
-			fmt.Printf("Current rate limit: %d remaining out of %d\n",
-				rateLimit.Rate.Remaining, rateLimit.Rate.Limit)
-
-			resetTime := time.Unix(int64(rateLimit.Rate.Reset), 0)
-			fmt.Printf("Reset time: %s (in %s)\n",
-				resetTime.Format(time.RFC3339),
-				time.Until(resetTime).Round(time.Minute))
-
-			issueCount := len(dataMaps)
-			if rateLimit.Rate.Remaining < issueCount {
-				fmt.Printf("Warning: Not enough rate limit remaining (%d) for %d issues\n",
-					rateLimit.Rate.Remaining, issueCount)
-				fmt.Printf("You may hit the rate limit during execution.\n")
-				fmt.Printf("Do you want to continue? (y/N): ")
-				var response string
-				fmt.Scanln(&response)
-				response = strings.ToLower(strings.TrimSpace(response))
-				if response != "y" && response != "yes" {
-					fmt.Println("Aborted.")
-					os.Exit(0)
+			fmt.Fprintf(os.Stderr, "  %s\n", name)
+		}
+	}
+}
+
+// runValidate renders every row and checks the issues against GitHub's limits
+// without contacting GitHub, exiting non-zero when any problem is found
+func runValidate(args []string) {
+	opts := parseFlags(args)
+	if opts.showHelp {
+		printHelp()
+		os.Exit(0)
+	}
+
+	if (opts.templateFile == "" && opts.templateDir == "") || opts.csvFile == "" {
+		fmt.Println("Error: --template (or --template-dir) and --csv are required")
+		os.Exit(1)
+	}
+
+	csvParser := csv.NewParser()
+	templateRenderer := template.NewRenderer()
+	templateParser := template.NewParser()
+
+	sources, rowCount, warnings, failures := loadSources(opts, nil, csvParser, templateRenderer, templateParser)
+	for _, warning := range warnings {
+		fmt.Println(warning)
+	}
+
+	issues, renderFailures := renderIssues(opts, templateRenderer, templateParser, sources)
+	failures = append(failures, renderFailures...)
+
+	// Problems refer to the CSV columns that fill each field of the issue's template
+	columns := make(map[*input.Document]map[string][]string)
+	items := make([]validate.Item, len(issues))
+	for i, planned := range issues {
+		fields, ok := columns[planned.document]
+		if !ok {
+			fields = templateRenderer.FieldVariables(planned.document.Content)
+			if opts.groupBy != "" {
+				for field, names := range fields {
+					fields[field] = input.GroupVariableColumns(names)
 				}
-			} else {
-				fmt.Printf("Rate limit looks sufficient for %d issues\n", issueCount)
 			}
+			columns[planned.document] = fields
 		}
+
+		source := planned.source
+		if planned.combination != "" {
+			source += " (" + planned.combination + ")"
+		}
+		items[i] = validate.Item{Source: source, Issue: planned.issue, Columns: fields}
 	}
+	problems := validate.NewValidator(opts.repo).Check(items)
 
-	// Process template and create issues
-	for _, data := range dataMaps {
-		// Render template with data
-		processedContent, err := templateRenderer.Render(string(tmplContent), data)
-		if err != nil {
-			fmt.Printf("Failed to process template: %v\n", err)
-			continue
+	if len(failures) > 0 || len(problems) > 0 {
+		fmt.Printf("Found %d problems:\n", len(failures)+len(problems))
+		for _, failure := range failures {
+			fmt.Printf(" - failed to %s\n", failure)
+		}
+		for _, problem := range problems {
+			fmt.Printf(" - %s\n", problem)
+		}
+		os.Exit(1)
+	}
+
+	fmt.Printf("OK: %d issues from %d rows are valid\n", len(issues), rowCount)
+}
+
+// runTemplates lists the issue templates of the local checkout or --repo
+func runTemplates(args []string) {
+	if len(args) == 0 || args[0] != "list" {
+		fmt.Println("Usage: gh issue-bulk-create templates list [--repo [HOST/]OWNER/REPO]")
+		os.Exit(1)
+	}
+	opts := parseFlags(args[1:])
+	if opts.showHelp {
+		printHelp()
+		os.Exit(0)
+	}
+
+	catalog, location := templateCatalog(opts, func() *github.Client { return newGitHubClient(opts) })
+	files, err := catalog.ListTemplates()
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(files) == 0 {
+		fmt.Printf("No issue templates in %s\n", location)
+		return
+	}
+
+	width := 0
+	for _, file := range files {
+		if len(file) > width {
+			width = len(file)
 		}
+	}
 
-		// Parse issue template to get issue data
-		issue, err := templateParser.ParseIssueTemplate(processedContent)
+	fmt.Printf("Issue templates in %s:\n", location)
+	for _, file := range files {
+		content, err := catalog.ReadTemplate(file)
 		if err != nil {
-			fmt.Printf("Failed to parse issue template: %v\n", err)
+			fmt.Printf("  %-*s  (%v)\n", width, file, err)
 			continue
 		}
+		name, about := template.DescribeTemplate(file, content)
+		description := name
+		if about != "" {
+			description += " - " + about
+		}
+		fmt.Printf("  %-*s  %s\n", width, file, description)
+	}
+	fmt.Println("Use a template with --template NAME, e.g. --template " + template.TemplateName(files[0]))
+}
 
-		if opts.dryRun {
-			// Dry run: Show issue content
-			fmt.Println("==== Issue Content ====")
-			fmt.Printf("Title: %s\n", issue.Title)
-			fmt.Printf("Labels: %v\n", issue.Labels)
-			fmt.Printf("Assignees: %v\n", issue.Assignees)
-			fmt.Printf("Body:\n%s\n", issue.Body)
-			fmt.Println("=====================")
-		} else {
-			// Create issue
-			response, err := githubClient.CreateIssue(issue, targetRepo)
-			if err != nil {
-				fmt.Printf("Failed to create issue: %v\n", err)
-			} else {
-				fmt.Printf("Issue #%d created: %s\n", response.Number, response.URL)
-			}
+// validateOptions checks the target and API options, exiting on invalid values
+func validateOptions(opts CommandLineOptions) {
+	switch opts.target {
+	case targetIssue, targetDiscussion:
+	case targetProject:
+		if _, _, err := github.ParseProjectRef(opts.project); err != nil {
+			fmt.Printf("Error: --target project requires --project OWNER/NUMBER: %v\n", err)
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf("Error: Invalid target '%s': must be '%s', '%s' or '%s'\n", opts.target, targetIssue, targetDiscussion, targetProject)
+		os.Exit(1)
+	}
+
+	switch opts.api {
+	case apiREST:
+	case apiGraphQL:
+		if opts.target != targetIssue {
+			fmt.Printf("Error: --api %s can only be used with --target %s\n", apiGraphQL, targetIssue)
+			os.Exit(1)
 		}
+		if opts.batchSize <= 0 {
+			fmt.Println("Error: --batch-size must be a positive number")
+			os.Exit(1)
+		}
+	default:
+		fmt.Printf("Error: Invalid API '%s': must be '%s' or '%s'\n", opts.api, apiREST, apiGraphQL)
+		os.Exit(1)
+	}
+}
+
+// newGitHubClient initializes the GitHub client for the host and authentication options
+func newGitHubClient(opts CommandLineOptions) *github.Client {
+	tokens, err := tokenSource(opts)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	githubClient, err := github.NewClient(opts.hostname, tokens)
+	if err != nil {
+		fmt.Printf("Failed to initialize GitHub API client: %v\n", err)
+		os.Exit(1)
+	}
+	return githubClient
+}
+
+// prepareIssues renders every issue from the template and CSV, resolves their
+// repositories and assignees and runs the checks that must pass before
+// anything is created. It exits when a check fails.
+func prepareIssues(opts CommandLineOptions, githubClient *github.Client) ([]*plannedIssue, []*repoGroup, *models.Project) {
+	// Initialize components
+	csvParser := csv.NewParser()
+	templateRenderer := template.NewRenderer()
+	templateParser := template.NewParser()
+
+	sources, _, warnings, failures := loadSources(opts, func() *github.Client { return githubClient }, csvParser, templateRenderer, templateParser)
+
+	if len(warnings) > 0 {
+		fmt.Println("Validation warnings:")
+		missing := false
+		for _, warning := range warnings {
+			fmt.Println(" -", warning)
+			missing = missing || strings.Contains(warning, "missing from CSV headers")
+		}
+
+		if missing {
+			fmt.Println("These missing variables will be left empty in the generated issues.")
+			fmt.Println("Do you want to continue? (y/N)")
+			var response string
+			fmt.Scanln(&response)
+			response = strings.ToLower(strings.TrimSpace(response))
+			if response != "y" && response != "yes" {
+				fmt.Println("Aborted.")
+				os.Exit(0)
+			}
+		}
+	}
+
+	// Render and parse every source before touching GitHub so that per-row
+	// repositories are known up front
+	issues, renderFailures := renderIssues(opts, templateRenderer, templateParser, sources)
+	failures = append(failures, renderFailures...)
+	for _, failure := range failures {
+		fmt.Printf("Failed to %s\n", failure)
+	}
+
+	var err error
+	var project *models.Project
+	var repos []*repoGroup
+	if opts.target == targetProject {
+		// Project drafts are not tied to a repository; group them under the project
+		for _, planned := range issues {
+			planned.repo = opts.project
+		}
+		repos = groupByRepo(issues)
+		fmt.Printf("Target project: %s\n", opts.project)
+		warnUnsupportedProjectFields(issues)
+
+		// Resolve the project and every field value up front so that no draft
+		// is created when any row refers to a missing field or option
+		if !opts.dryRun {
+			owner, number, _ := github.ParseProjectRef(opts.project)
+			project, err = githubClient.GetProject(owner, number)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				os.Exit(1)
+			}
+			failed := false
+			for _, planned := range issues {
+				if _, err := github.ProjectFieldValues(project, planned.issue.Fields); err != nil {
+					fmt.Printf("Error: %s: %v\n", planned.source, err)
+					failed = true
+				}
+			}
+			if failed {
+				os.Exit(1)
+			}
+		}
+	} else {
+		// Determine repository for rows without a per-row repo
+		defaultRepo := opts.repo
+		for _, planned := range issues {
+			if planned.repo != "" {
+				continue
+			}
+			if defaultRepo == "" {
+				// If not specified as a flag, try to get from current directory
+				defaultRepo, err = githubClient.GetCurrentRepository()
+				if err != nil {
+					fmt.Printf("Failed to determine repository: %v\n", err)
+					fmt.Println("Please specify the repository using --repo option, a repo front matter value, or run in a git repository")
+					os.Exit(1)
+				}
+			}
+			planned.repo = defaultRepo
+		}
+
+		for _, planned := range issues {
+			if _, err := models.ParseRepoRef(planned.repo); err != nil {
+				fmt.Printf("Error: Invalid repository for %s: %v\n", planned.source, err)
+				os.Exit(1)
+			}
+		}
+
+		if opts.skipExisting {
+			issues = skipExistingIssues(issues, githubClient.Host())
+		}
+
+		repos = groupByRepo(issues)
+		if len(repos) == 1 {
+			fmt.Printf("Target repository: %s\n", repos[0].repo)
+		} else {
+			fmt.Printf("Target repositories (%d):\n", len(repos))
+			for _, group := range repos {
+				fmt.Printf(" - %s: %d issues\n", group.repo, len(group.issues))
+			}
+		}
+	}
+
+	// Expand team mentions and CODEOWNERS entries, then pick assignees from
+	// assignee pools for issues that still have none
+	if opts.target == targetIssue {
+		if err := resolveAssignees(issues, assignee.NewResolver(githubClient)); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := assignFromPools(issues, githubClient); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Check repositories, permissions and assignees for every row before any
+	// writes, reporting all problems at once
+	if opts.target == targetIssue {
+		if !runPreflight(issues, githubClient) {
+			os.Exit(1)
+		}
+	}
+
+	if opts.target == targetDiscussion {
+		warnUnsupportedDiscussionFields(issues)
+	}
+
+	// Resolve discussion categories up front so that no discussion is created
+	// when any row refers to a missing category
+	if opts.target == targetDiscussion && !opts.dryRun {
+		failed := false
+		for _, planned := range issues {
+			if _, err := githubClient.GetDiscussionCategory(planned.repo, planned.issue.Category); err != nil {
+				fmt.Printf("Error: %s: %v\n", planned.source, err)
+				failed = true
+			}
+		}
+		if failed {
+			os.Exit(1)
+		}
+	}
+
+	return issues, repos, project
+}
+
+// readTemplate reads the template given by --template. It exits when the
+// template cannot be read or parsed.
+func readTemplate(opts CommandLineOptions, newClient func() *github.Client, loader *input.Loader) *input.Template {
+	catalog, file, content := loadTemplateFile(opts, newClient)
+	tmpl, err := loader.Parse(catalog, file, content)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	return tmpl
+}
+
+// loadTemplateFile reads the template given by --template: a file, or the
+// name of one of the repository's issue templates such as "bug_report". It
+// returns the catalog the template is in, its file name in the catalog and
+// its content, and exits when neither is found. newClient is nil when GitHub
+// must not be contacted.
+func loadTemplateFile(opts CommandLineOptions, newClient func() *github.Client) (template.Catalog, string, []byte) {
+	content, err := os.ReadFile(opts.templateFile)
+	if err == nil {
+		return template.NewDirCatalog(filepath.Dir(opts.templateFile)), filepath.Base(opts.templateFile), content
+	}
+	if !os.IsNotExist(err) || strings.ContainsAny(opts.templateFile, `/\`) {
+		fmt.Printf("Failed to read template file: %v\n", err)
+		os.Exit(1)
+	}
+
+	catalog, location := templateCatalog(opts, newClient)
+	if catalog == nil {
+		fmt.Printf("Error: Template file '%s' not found, and there is no %s directory in this checkout\n", opts.templateFile, template.TemplateDir)
+		os.Exit(1)
+	}
+	file, err := template.FindTemplate(catalog, opts.templateFile)
+	if err == nil {
+		content, err = catalog.ReadTemplate(file)
+	}
+	if err != nil {
+		fmt.Printf("Error: %s: %v\n", location, err)
+		os.Exit(1)
+	}
+
+	// Standard error keeps the message out of CSV written to standard output
+	fmt.Fprintf(os.Stderr, "Using template %s from %s\n", file, location)
+	return catalog, file, content
+}
+
+// templateCatalog returns the issue templates of the local checkout when the
+// target is the current repository (or GitHub must not be contacted), and
+// otherwise those of the target repository. It returns nil when there is no
+// catalog to look in.
+func templateCatalog(opts CommandLineOptions, newClient func() *github.Client) (template.Catalog, string) {
+	if opts.repo == "" || newClient == nil {
+		if dir, ok := localTemplateDir(); ok {
+			return template.NewDirCatalog(dir), dir
+		}
+	}
+	if newClient == nil {
+		return nil, ""
+	}
+
+	githubClient := newClient()
+	repo := opts.repo
+	if repo == "" {
+		var err error
+		repo, err = githubClient.GetCurrentRepository()
+		if err != nil {
+			fmt.Printf("Failed to determine repository: %v\n", err)
+			fmt.Println("Please specify the repository using --repo option, or run in a git repository")
+			os.Exit(1)
+		}
+	}
+	return github.NewTemplateCatalog(githubClient, repo), repo
+}
+
+// localTemplateDir finds the issue template directory of the git checkout
+// containing the working directory
+func localTemplateDir() (string, bool) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", false
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			templates := filepath.Join(dir, filepath.FromSlash(template.TemplateDir))
+			info, err := os.Stat(templates)
+			return templates, err == nil && info.IsDir()
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// readData reads the CSV file and returns its headers, the rows selected by
+// --rows, --where and --limit mapped by header, and the 1-based number of each
+// selected row in the CSV. Headers and rows are those produced by --mapping,
+// if given. It also returns the columns read by --mapping and --where, which
+// are used even when no template refers to them. It exits when the CSV cannot
+// be read.
+func readData(opts CommandLineOptions, csvParser *csv.Parser) ([]string, []map[string]string, []int, []string) {
+	records, headers, err := csvParser.Parse(opts.csvFile)
+	if err != nil {
+		// Provide more user-friendly error messages for CSV validation errors
+		if strings.Contains(err.Error(), "CSV file is empty") {
+			fmt.Printf("Error: The CSV file '%s' is empty. Please add headers and data.\n", opts.csvFile)
+		} else if strings.Contains(err.Error(), "empty header") {
+			fmt.Printf("Error: CSV validation failed: %v\n", err)
+			fmt.Println("All columns in the CSV file must have headers. Please check your CSV file.")
+		} else {
+			fmt.Printf("Failed to read CSV file: %v\n", err)
+		}
+		os.Exit(1)
+	}
+
+	// Map records to data maps, adapted to the template's columns by --mapping
+	dataMaps := csvParser.MapRecords(records, headers)
+	var readColumns []string
+	if opts.mapping != "" {
+		m, err := mapping.Load(opts.mapping)
+		if err == nil {
+			headers, dataMaps, err = m.Apply(headers, dataMaps)
+		}
+		if err != nil {
+			fmt.Printf("Error: --mapping: %v\n", err)
+			os.Exit(1)
+		}
+		readColumns = m.ReadColumns()
+	}
+
+	if opts.groupBy != "" && !slices.Contains(headers, opts.groupBy) {
+		fmt.Printf("Error: Group-by column '%s' not found in CSV headers\n", opts.groupBy)
+		os.Exit(1)
+	}
+
+	selector, err := filter.NewSelector(opts.rows, opts.where, opts.limit)
+	if err == nil {
+		err = selector.CheckColumns(headers)
+	}
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	readColumns = append(readColumns, selector.WhereColumns()...)
+
+	selected := selectRows(opts, selector, dataMaps)
+	selectedMaps := make([]map[string]string, len(selected))
+	for i, n := range selected {
+		selectedMaps[i] = dataMaps[n-1]
+	}
+	return headers, selectedMaps, selected, readColumns
+}
+
+// selectRows applies --rows, --where and --limit to the rows and returns the
+// numbers of those selected. A dry run lists the rows left out and why.
+func selectRows(opts CommandLineOptions, selector *filter.Selector, dataMaps []map[string]string) []int {
+	selected, excluded := selector.Select(dataMaps)
+	if selector.IsEmpty() {
+		return selected
+	}
+	fmt.Printf("Row selection: %d of %d rows selected\n", len(selected), len(dataMaps))
+	if opts.dryRun {
+		for _, exclusion := range excluded {
+			fmt.Printf("  Excluded row %d: %s\n", exclusion.Row, exclusion.Reason)
+		}
+	}
+	if len(selected) == 0 {
+		fmt.Println("Error: No rows selected")
+		os.Exit(1)
+	}
+	return selected
+}
+
+// loadSources reads the CSV data, builds the sources issues are rendered from
+// and gives each its template. It returns the sources, the number of CSV
+// rows, header warnings, and failures for sources whose template cannot be
+// loaded, which are left out. newClient is nil when GitHub must not be contacted.
+func loadSources(opts CommandLineOptions, newClient func() *github.Client, csvParser *csv.Parser, templateRenderer *template.Renderer, templateParser *template.Parser) ([]*input.Source, int, []string, []string) {
+	headers, dataMaps, rowNumbers, readColumns := readData(opts, csvParser)
+	loader := input.NewLoader(opts.matrix.toMatrix(), templateRenderer, templateParser)
+
+	var listColumns []string
+	if opts.groupBy != "" {
+		listColumns = append(listColumns, csv.DefaultListColumns...)
+		for _, column := range strings.Split(opts.listColumns, ",") {
+			if column = strings.TrimSpace(column); column != "" {
+				listColumns = append(listColumns, column)
+			}
+		}
+	}
+	sources := input.BuildSources(csvParser, dataMaps, rowNumbers, opts.groupBy, listColumns)
+	if opts.groupBy != "" {
+		fmt.Printf("Grouping: %d rows grouped into %d issues by '%s'\n", len(dataMaps), len(sources), opts.groupBy)
+	}
+
+	var failures []string
+	if opts.templateDir == "" {
+		tmpl := readTemplate(opts, newClient, loader)
+		for _, source := range sources {
+			source.Template = tmpl
+		}
+	} else {
+		if !slices.Contains(headers, input.TemplateColumn) && opts.templateFile == "" {
+			fmt.Printf("Error: --template-dir requires a '%s' column or a default --template\n", input.TemplateColumn)
+			os.Exit(1)
+		}
+
+		templates := input.NewTemplateDir(opts.templateDir, loader)
+		var defaultTmpl *input.Template
+		if opts.templateFile != "" {
+			var err error
+			defaultTmpl, err = templates.Load(opts.templateFile)
+			if err != nil {
+				defaultTmpl = readTemplate(opts, newClient, loader)
+			}
+		}
+		sources, failures = input.AssignTemplates(sources, templates, defaultTmpl)
+	}
+
+	warnings, err := input.CheckHeaders(csvParser, headers, readColumns, sources, opts.groupBy != "", opts.templateDir != "")
+	if err != nil {
+		fmt.Printf("Error: Failed to validate CSV headers: %v\n", err)
+		os.Exit(1)
+	}
+	return sources, len(dataMaps), warnings, failures
+}
+
+// renderIssues renders and parses the issue of every source and matrix
+// combination with the source's template, preparing the data of each source
+// first when the template is an issue form. Sources that fail are skipped and described in the returned
+// failures, such as "process template for row 3: ...".
+func renderIssues(opts CommandLineOptions, templateRenderer *template.Renderer, templateParser *template.Parser, sources []*input.Source) ([]*plannedIssue, []string) {
+	var issues []*plannedIssue
+	var failures []string
+	expandedRows := 0
+	skipped := 0
+	for _, source := range sources {
+		// Issue form fields are checked and formatted the way GitHub renders them
+		tmpl := source.Template
+		if tmpl.Form != nil {
+			data, err := tmpl.Form.Prepare(source.Data)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("fill in issue form for %s: %v", source.Label, err))
+				continue
+			}
+			source = &input.Source{Label: source.Label, Data: data, Rows: source.Rows, Indexes: source.Indexes, Template: tmpl}
+		}
+
+		expanded := false
+		for i, document := range tmpl.Documents {
+			// Documents whose condition the row does not match create no issue
+			if document.When != nil && !document.When.Match(source.Data) {
+				skipped++
+				continue
+			}
+			label := source.Label
+			if len(tmpl.Documents) > 1 {
+				label = fmt.Sprintf("%s (document %d)", source.Label, i+1)
+			}
+
+			// Render template with source data to read its matrix
+			processedContent, err := source.Render(templateRenderer, document.Content, nil)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("process template for %s: %v", label, err))
+				continue
+			}
+
+			matrix, err := templateParser.ParseMatrix(processedContent)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("parse matrix for %s: %v", label, err))
+				continue
+			}
+			matrix = matrix.Merge(opts.matrix.toMatrix())
+
+			combinations, err := matrix.Expand()
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("expand matrix for %s: %v", label, err))
+				continue
+			}
+			if matrix.IsEmpty() {
+				combinations = []map[string]string{nil}
+			} else {
+				expanded = true
+			}
+
+			for _, combination := range combinations {
+				// Re-render with the combination's variables on top of the source data
+				if combination != nil {
+					processedContent, err = source.Render(templateRenderer, document.Content, combination)
+					if err != nil {
+						failures = append(failures, fmt.Sprintf("process template for %s (%s): %v", label, formatCombination(matrix, combination), err))
+						continue
+					}
+				}
+
+				// Parse issue template to get issue data
+				issue, err := templateParser.ParseIssueTemplate(processedContent)
+				if err != nil {
+					failures = append(failures, fmt.Sprintf("parse issue template for %s: %v", label, err))
+					continue
+				}
+
+				issues = append(issues, &plannedIssue{
+					source:      label,
+					combination: formatCombination(matrix, combination),
+					issue:       issue,
+					repo:        issue.Repo,
+					url:         source.Data["url"],
+					document:    document,
+				})
+			}
+		}
+		if expanded {
+			expandedRows++
+		}
+	}
+
+	if expandedRows > 0 {
+		fmt.Printf("Matrix expansion: %d rows expanded into %d issues\n", len(sources), len(issues))
+	}
+	if skipped > 0 {
+		fmt.Printf("Skipped %d issues whose when condition does not match their row\n", skipped)
+	}
+	return issues, failures
+}
+
+// checkRateLimit warns and asks for confirmation when the remaining rate limit
+// of a host is lower than the number of items to create in its repositories
+func checkRateLimit(opts CommandLineOptions, githubClient *github.Client, repos []*repoGroup) {
+	// Each host has its own token and limit, shared by its repositories
+	var hosts []string
+	issueCounts := make(map[string]int)
+	repoCounts := make(map[string]int)
+	for _, group := range repos {
+		host, err := githubClient.RepoHost(group.repo)
+		if err != nil {
+			fmt.Printf("Warning: Failed to check rate limit for %s: %v\n", group.repo, err)
+			continue
+		}
+		if _, ok := issueCounts[host]; !ok {
+			hosts = append(hosts, host)
+		}
+		issueCounts[host] += len(group.issues)
+		repoCounts[host]++
+	}
+
+	for _, host := range hosts {
+		rateLimit, err := githubClient.GetHostRateLimit(host)
+		if err != nil {
+			fmt.Printf("Warning: Failed to check rate limit for %s: %v\n", host, err)
+			continue
+		}
+
+		// Discussions, project drafts and --api graphql use GraphQL, which has its own limit
+		rate := rateLimit.Rate
+		if opts.target == targetDiscussion || opts.target == targetProject || opts.api == apiGraphQL {
+			rate = rateLimit.Resources.GraphQL
+		}
+
+		fmt.Printf("Current rate limit on %s: %d remaining out of %d\n",
+			host, rate.Remaining, rate.Limit)
+
+		resetTime := time.Unix(int64(rate.Reset), 0)
+		fmt.Printf("Reset time: %s (in %s)\n",
+			resetTime.Format(time.RFC3339),
+			time.Until(resetTime).Round(time.Minute))
+
+		issueCount := issueCounts[host]
+		if rate.Remaining < issueCount {
+			fmt.Printf("Warning: Not enough rate limit remaining on %s (%d) for %d %ss across %d repositories\n",
+				host, rate.Remaining, issueCount, opts.target, repoCounts[host])
+			fmt.Printf("You may hit the rate limit during execution.\n")
+			fmt.Printf("Do you want to continue? (y/N): ")
+			var response string
+			fmt.Scanln(&response)
+			response = strings.ToLower(strings.TrimSpace(response))
+			if response != "y" && response != "yes" {
+				fmt.Println("Aborted.")
+				os.Exit(0)
+			}
+		} else {
+			fmt.Printf("Rate limit on %s looks sufficient for %d %ss\n", host, issueCount, opts.target)
+		}
+	}
+}
+
+// createPlannedIssues creates every planned issue, discussion or project draft,
+// or prints them in a dry run, followed by a summary per repository
+func createPlannedIssues(opts CommandLineOptions, githubClient *github.Client, issues []*plannedIssue, repos []*repoGroup, project *models.Project) {
+	// Created issues are recorded so that the run can be undone
+	run := runlog.NewRun(githubClient.Host(), time.Now())
+	recordFailed := false
+
+	// Issues are created through REST one at a time, or through GraphQL in batches
+	// once every label, assignee and milestone ID has been resolved
+	var issueClient github.ClientInterface = githubClient
+	batchSize := 1
+	if opts.api == apiGraphQL && !opts.dryRun {
+		graphqlClient := github.NewGraphQLIssueClient(githubClient, opts.batchSize)
+		if !resolveIssueIDs(issues, graphqlClient) {
+			os.Exit(1)
+		}
+		issueClient = graphqlClient
+		batchSize = opts.batchSize
+	}
+
+	// Create issues
+	stopped := false
+	for i, planned := range issues {
+		issue := planned.issue
+		targetRepo := planned.repo
+
+		if opts.dryRun {
+			// Dry run: Show issue content
+			switch opts.target {
+			case targetDiscussion:
+				fmt.Println("==== Discussion Content ====")
+			case targetProject:
+				fmt.Println("==== Project Draft Content ====")
+			default:
+				fmt.Println("==== Issue Content ====")
+			}
+			if opts.target == targetProject {
+				fmt.Printf("Project: %s\n", targetRepo)
+			} else {
+				fmt.Printf("Repository: %s\n", targetRepo)
+			}
+			if planned.combination != "" {
+				fmt.Printf("Matrix: %s\n", planned.combination)
+			}
+			fmt.Printf("Title: %s\n", issue.Title)
+			if opts.target == targetDiscussion {
+				fmt.Printf("Category: %s\n", issue.Category)
+				fmt.Printf("Body:\n%s\n", issue.Body)
+				for i, comment := range issue.Comments {
+					fmt.Printf("Comment %d/%d:\n%s\n", i+1, len(issue.Comments), comment)
+				}
+				fmt.Println("=====================")
+				continue
+			}
+			if opts.target == targetProject {
+				for _, name := range sortedFieldNames(issue.Fields) {
+					fmt.Printf("Field %s: %s\n", name, issue.Fields[name])
+				}
+				fmt.Printf("Body:\n%s\n", issue.Body)
+				fmt.Println("=====================")
+				continue
+			}
+			fmt.Printf("Labels: %v\n", issue.Labels)
+			fmt.Printf("Assignees: %v\n", issue.Assignees)
+			if len(planned.assigneeSources) > 0 {
+				for _, login := range issue.Assignees {
+					fmt.Printf("  %s: %s\n", login, planned.assigneeSources[login])
+				}
+			}
+			if issue.Type != "" {
+				fmt.Printf("Type: %s\n", issue.Type)
+			}
+			if issue.HasStateChanges() {
+				fmt.Printf("State: %s\n", formatIssueState(issue))
+			}
+			fmt.Printf("Body:\n%s\n", issue.Body)
+			for i, comment := range issue.Comments {
+				fmt.Printf("Comment %d/%d:\n%s\n", i+1, len(issue.Comments), comment)
+			}
+			fmt.Println("=====================")
+			continue
+		}
+
+		if opts.target == targetDiscussion {
+			createDiscussion(githubClient, planned)
+			continue
+		}
+
+		if opts.target == targetProject {
+			item, err := githubClient.CreateProjectDraft(project, issue)
+			if err != nil {
+				fmt.Printf("Failed to create project draft '%s': %v\n", issue.Title, err)
+				planned.err = err
+				continue
+			}
+			planned.projectItem = item
+			fmt.Printf("Project draft created: %s (%s)\n", issue.Title, item.ID)
+			continue
+		}
+
+		// Create this issue together with the rest of its batch. Once a
+		// request has an unknown outcome, nothing more is sent.
+		if planned.response == nil && planned.err == nil {
+			if stopped {
+				planned.err = github.ErrNotSent
+				continue
+			}
+			createIssues(issueClient, issues[i:min(i+batchSize, len(issues))])
+		}
+		if errors.Is(planned.err, github.ErrUnknownOutcome) {
+			fmt.Printf("Issue '%s' in %s may or may not have been created: %v\n", issue.Title, targetRepo, planned.err)
+			stopped = true
+			continue
+		}
+		if planned.err != nil {
+			fmt.Printf("Failed to create issue in %s: %v\n", targetRepo, planned.err)
+			continue
+		}
+		response := planned.response
+		fmt.Printf("Issue #%d created: %s\n", response.Number, response.URL)
+		run.Add(runlog.Issue{
+			Source: planned.source,
+			Repo:   targetRepo,
+			Number: response.Number,
+			NodeID: response.NodeID,
+			URL:    response.URL,
+			Title:  issue.Title,
+			State:  issue.State,
+		})
+		// The record is kept up to date in case the run is interrupted
+		if _, err := runlog.Save(opts.stateDir, run); err != nil && !recordFailed {
+			fmt.Printf("Warning: failed to record run: %v\n", err)
+			recordFailed = true
+		}
+
+		// Post follow-up comments, then close, lock or pin the issue as requested
+		comments, stateErr := github.FollowUp(githubClient, issue, targetRepo, response)
+		for i, comment := range comments {
+			if comment.Err != nil {
+				fmt.Printf("  Failed to post comment %d/%d on issue #%d: %v\n", i+1, len(comments), response.Number, comment.Err)
+			} else {
+				fmt.Printf("  Comment %d/%d posted: %s\n", i+1, len(comments), comment.Response.URL)
+			}
+		}
+		if issue.HasStateChanges() {
+			if stateErr != nil {
+				fmt.Printf("  Failed to apply state to issue #%d: %v\n", response.Number, stateErr)
+			} else {
+				fmt.Printf("  State applied: %s\n", formatIssueState(issue))
+			}
+		}
+	}
+
+	if stopped {
+		fmt.Println("Stopped: a request failed with an unknown outcome. Check the repositories for the issues reported above before running the remaining rows again.")
+	}
+	if !opts.dryRun {
+		printSummary(repos)
+	}
+
+	if len(run.Issues) > 0 && !recordFailed {
+		fmt.Printf("Run %s recorded. To undo it: gh issue-bulk-create undo %s\n", run.ID, run.ID)
+	}
+}
+
+// plannedIssue is a rendered issue together with the row it came from,
+// its target repository and the outcome of creating it
+type plannedIssue struct {
+	source      string
+	combination string
+	document    *input.Document
+	issue       *models.Issue
+	repo        string
+	// url is the row's url column, naming the issue it was exported from
+	url         string
+	response    *models.IssueResponse
+	discussion  *models.DiscussionResponse
+	projectItem *models.ProjectItemResponse
+	// assigneeSources describes where resolved assignees came from, by login
+	assigneeSources map[string]string
+	err             error
+}
+
+// skipExistingIssues leaves out issues whose url column links to an issue of
+// the repository they would be created in, such as the rows of an export of
+// that repository. It exits when nothing is left to create.
+func skipExistingIssues(issues []*plannedIssue, host string) []*plannedIssue {
+	var kept []*plannedIssue
+	for _, planned := range issues {
+		repo, _ := models.ParseRepoRef(planned.repo)
+		if repo.Host == "" {
+			repo.Host = host
+		}
+		if number, ok := csv.ExistingIssue(planned.url, repo); ok {
+			fmt.Printf("Skipping %s: %s#%d already exists\n", planned.source, planned.repo, number)
+			continue
+		}
+		kept = append(kept, planned)
+	}
+	if len(kept) == 0 {
+		fmt.Println("Every issue already exists. Nothing to create.")
+		os.Exit(0)
+	}
+	return kept
+}
+
+// assignFromPools fills in the assignee of every issue that has an assignee
+// pool but no explicit assignees. Pools with the same configuration share
+// their state, so rotation and load balancing continue across rows.
+func assignFromPools(issues []*plannedIssue, client assignee.OpenIssueCounter) error {
+	pools := make(map[string]*assignee.Pool)
+
+	for _, planned := range issues {
+		config := planned.issue.AssigneePool
+		if config == nil || len(planned.issue.Assignees) > 0 {
+			continue
+		}
+
+		pool, ok := pools[config.Key()]
+		if !ok {
+			var err error
+			pool, err = assignee.NewPool(config.Strategy, config.Users, config.Seed, client)
+			if err != nil {
+				return fmt.Errorf("%s: %v", planned.source, err)
+			}
+			pools[config.Key()] = pool
+		}
+
+		user, err := pool.Next(planned.repo)
+		if err != nil {
+			return fmt.Errorf("%s: %v", planned.source, err)
+		}
+		planned.issue.Assignees = []string{user}
+		planned.assigneeSources = map[string]string{user: "assignee pool, " + pool.Strategy()}
+	}
+
+	return nil
+}
+
+// runPreflight checks every planned issue and prints all problems found.
+// It returns false if any problem was found.
+func runPreflight(issues []*plannedIssue, client preflight.RepositoryChecker) bool {
+	items := make([]preflight.Item, 0, len(issues))
+	for _, planned := range issues {
+		items = append(items, preflight.Item{Source: planned.source, Repo: planned.repo, Issue: planned.issue})
+	}
+
+	problems, err := preflight.NewChecker(client).Check(items)
+	if err != nil {
+		fmt.Printf("Error: Preflight check failed: %v\n", err)
+		return false
+	}
+	if len(problems) == 0 {
+		fmt.Println("Preflight checks passed")
+		return true
+	}
+
+	fmt.Printf("Preflight found %d problems:\n", len(problems))
+	for _, problem := range problems {
+		fmt.Println(" -", problem)
+	}
+	fmt.Println("No issues were created.")
+	return false
+}
+
+// resolveAssignees expands team mentions and CODEOWNERS entries into user
+// logins, recording where each assignee came from
+func resolveAssignees(issues []*plannedIssue, resolver *assignee.Resolver) error {
+	for _, planned := range issues {
+		issue := planned.issue
+		if !assignee.NeedsResolution(issue.Assignees, issue.CodeownersPath) {
+			continue
+		}
+
+		assignments, warnings, err := resolver.Resolve(planned.repo, issue.Assignees, issue.CodeownersPath)
+		if err != nil {
+			return fmt.Errorf("%s: %v", planned.source, err)
+		}
+		for _, warning := range warnings {
+			fmt.Printf("Warning: %s: %s\n", planned.source, warning)
+		}
+
+		issue.Assignees = nil
+		planned.assigneeSources = make(map[string]string, len(assignments))
+		for _, assignment := range assignments {
+			issue.Assignees = append(issue.Assignees, assignment.Login)
+			planned.assigneeSources[assignment.Login] = assignment.Source
+		}
+	}
+
+	return nil
+}
+
+// createDiscussion creates a discussion and its follow-up comments for a planned issue
+func createDiscussion(client github.DiscussionClientInterface, planned *plannedIssue) {
+	issue := planned.issue
+
+	response, err := client.CreateDiscussion(issue, planned.repo)
+	if err != nil {
+		fmt.Printf("Failed to create discussion in %s: %v\n", planned.repo, err)
+		planned.err = err
+		return
+	}
+	planned.discussion = response
+	fmt.Printf("Discussion #%d created: %s\n", response.Number, response.URL)
+
+	for i, comment := range issue.Comments {
+		commentResponse, err := client.CreateDiscussionComment(planned.repo, response.ID, comment)
+		if err != nil {
+			fmt.Printf("  Failed to post comment %d/%d on discussion #%d: %v\n", i+1, len(issue.Comments), response.Number, err)
+		} else {
+			fmt.Printf("  Comment %d/%d posted: %s\n", i+1, len(issue.Comments), commentResponse.URL)
+		}
+	}
+}
+
+// warnUnsupportedProjectFields prints a warning when front matter sets
+// fields that project draft issues do not support
+func warnUnsupportedProjectFields(issues []*plannedIssue) {
+	for _, planned := range issues {
+		issue := planned.issue
+		if len(issue.Labels) > 0 || len(issue.Assignees) > 0 || issue.Milestone != "" || issue.HasStateChanges() || len(issue.Comments) > 0 {
+			fmt.Println("Warning: labels, assignees, milestone, state and comments are ignored when creating project drafts")
+			return
+		}
+	}
+}
+
+// sortedFieldNames returns the names of project field values in sorted order
+func sortedFieldNames(fields map[string]string) []string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// warnUnsupportedDiscussionFields prints a warning when front matter sets
+// fields that only apply to issues
+func warnUnsupportedDiscussionFields(issues []*plannedIssue) {
+	for _, planned := range issues {
+		issue := planned.issue
+		if len(issue.Labels) > 0 || len(issue.Assignees) > 0 || issue.Milestone != "" || issue.HasStateChanges() {
+			fmt.Println("Warning: labels, assignees, milestone and state are ignored when creating discussions")
+			return
+		}
+	}
+}
+
+// repoGroup holds the planned issues for a single repository
+type repoGroup struct {
+	repo   string
+	issues []*plannedIssue
+}
+
+// groupByRepo groups planned issues by target repository, keeping the
+// order in which repositories first appear
+func groupByRepo(issues []*plannedIssue) []*repoGroup {
+	var groups []*repoGroup
+	index := make(map[string]*repoGroup)
+	for _, planned := range issues {
+		group, ok := index[planned.repo]
+		if !ok {
+			group = &repoGroup{repo: planned.repo}
+			index[planned.repo] = group
+			groups = append(groups, group)
+		}
+		group.issues = append(group.issues, planned)
+	}
+	return groups
+}
+
+// formatCombination describes a matrix combination as NAME=VALUE pairs
+func formatCombination(matrix *template.Matrix, combination map[string]string) string {
+	var parts []string
+	for _, key := range matrix.Keys() {
+		if value, ok := combination[key]; ok {
+			parts = append(parts, key+"="+value)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// createIssues creates a batch of planned issues and records their responses or errors
+func createIssues(client github.ClientInterface, batch []*plannedIssue) {
+	requests := make([]github.IssueRequest, len(batch))
+	for i, planned := range batch {
+		requests[i] = github.IssueRequest{Issue: planned.issue, Repo: planned.repo}
+	}
+	for i, result := range client.CreateIssues(requests) {
+		batch[i].response = result.Response
+		batch[i].err = result.Err
+	}
+}
+
+// resolveIssueIDs resolves the node IDs every issue needs before anything is
+// created through GraphQL. It prints every problem and reports whether all
+// issues can be created.
+func resolveIssueIDs(issues []*plannedIssue, client *github.GraphQLIssueClient) bool {
+	requests := make([]github.IssueRequest, len(issues))
+	for i, planned := range issues {
+		requests[i] = github.IssueRequest{Issue: planned.issue, Repo: planned.repo}
+	}
+
+	errs := client.ResolveIssueIDs(requests)
+	if errs == nil {
+		return true
+	}
+	fmt.Println("Failed to resolve IDs for GraphQL issue creation:")
+	for i, err := range errs {
+		if err != nil {
+			fmt.Printf(" - %s: %v\n", issues[i].source, err)
+		}
+	}
+	fmt.Println("No issues were created.")
+	return false
+}
+
+// tokenSource returns the token source selected by the authentication flags,
+// or nil to use gh's login
+func tokenSource(opts CommandLineOptions) (auth.TokenSource, error) {
+	if opts.appID != 0 && opts.tokenEnv != "" {
+		return nil, fmt.Errorf("--app-id and --token-env cannot be used together")
+	}
+
+	if opts.appID != 0 {
+		if opts.appKeyFile == "" {
+			return nil, fmt.Errorf("--app-id requires --app-private-key")
+		}
+		key, err := os.ReadFile(opts.appKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GitHub App private key: %v", err)
+		}
+		return auth.NewAppTokenSource(opts.hostname, opts.appID, opts.appInstall, key)
+	}
+	if opts.appKeyFile != "" || opts.appInstall != 0 {
+		return nil, fmt.Errorf("--app-private-key and --app-installation-id require --app-id")
+	}
+
+	if opts.tokenEnv != "" {
+		return auth.EnvToken(opts.tokenEnv)
+	}
+	return nil, nil
+}
+
+// printSummary prints the number of created and failed issues per repository
+func printSummary(repos []*repoGroup) {
+	fmt.Println("==== Summary ====")
+	for _, group := range repos {
+		created, failed, unknown, notSent := 0, 0, 0, 0
+		for _, planned := range group.issues {
+			switch {
+			case errors.Is(planned.err, github.ErrUnknownOutcome):
+				unknown++
+			case errors.Is(planned.err, github.ErrNotSent):
+				notSent++
+			case planned.err != nil:
+				failed++
+			case planned.response != nil || planned.discussion != nil || planned.projectItem != nil:
+				created++
+			}
+		}
+		summary := fmt.Sprintf("%s: %d created, %d failed", group.repo, created, failed)
+		if unknown > 0 {
+			summary += fmt.Sprintf(", %d unknown (check the repository)", unknown)
+		}
+		if notSent > 0 {
+			summary += fmt.Sprintf(", %d not sent", notSent)
+		}
+		fmt.Println(summary)
+	}
+}
+
+// formatIssueState describes the post-creation state requested for an issue
+func formatIssueState(issue *models.Issue) string {
+	var parts []string
+	if issue.State == "closed" {
+		if issue.StateReason != "" {
+			parts = append(parts, fmt.Sprintf("closed (%s)", issue.StateReason))
+		} else {
+			parts = append(parts, "closed")
+		}
+	}
+	if issue.Locked {
+		if issue.LockReason != "" {
+			parts = append(parts, fmt.Sprintf("locked (%s)", issue.LockReason))
+		} else {
+			parts = append(parts, "locked")
+		}
+	}
+	if issue.Pinned {
+		parts = append(parts, "pinned")
 	}
+	return strings.Join(parts, ", ")
 }
@@ -2,6 +2,12 @@
 // It includes models for GitHub issues and related entities.
 package models
 
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
 // Issue represents a GitHub issue with its metadata
 type Issue struct {
 	Title     string   `json:"title"`
@@ -9,6 +15,39 @@ type Issue struct {
 	Labels    []string `json:"labels,omitempty"`
 	Assignees []string `json:"assignees,omitempty"`
 	Milestone string   `json:"milestone,omitempty"`
+	Comments  []string `json:"comments,omitempty"`
+	Repo      string   `json:"repo,omitempty"`
+	Category  string   `json:"category,omitempty"`
+	// Type is the name of an issue type defined by the organization
+	Type string `json:"type,omitempty"`
+
+	// Project field values by field name, used for project draft items
+	Fields map[string]string `json:"fields,omitempty"`
+
+	// State applied after creation
+	State       string `json:"state,omitempty"`
+	StateReason string `json:"state_reason,omitempty"`
+	Locked      bool   `json:"locked,omitempty"`
+	LockReason  string `json:"lock_reason,omitempty"`
+	Pinned      bool   `json:"pinned,omitempty"`
+
+	// CodeownersPath adds the CODEOWNERS owners of this path as assignees
+	CodeownersPath string `json:"codeowners_path,omitempty"`
+
+	// AssigneePool picks an assignee when Assignees is empty
+	AssigneePool *AssigneePool `json:"assignee_pool,omitempty"`
+}
+
+// AssigneePool describes how to pick an assignee from a list of users
+type AssigneePool struct {
+	Strategy string   `json:"strategy"`
+	Users    []string `json:"users"`
+	Seed     int64    `json:"seed,omitempty"`
+}
+
+// Key identifies pools with the same configuration so they share state across rows
+func (p *AssigneePool) Key() string {
+	return fmt.Sprintf("%s|%d|%s", p.Strategy, p.Seed, strings.Join(p.Users, ","))
 }
 
 // NewIssue creates a new Issue with the given title and body
@@ -37,10 +76,192 @@ func (i *Issue) WithMilestone(milestone string) *Issue {
 	return i
 }
 
+// WithComments adds follow-up comments to the issue
+func (i *Issue) WithComments(comments []string) *Issue {
+	i.Comments = comments
+	return i
+}
+
+// HasStateChanges reports whether the issue needs follow-up calls after creation
+// to close, lock or pin it
+func (i *Issue) HasStateChanges() bool {
+	return i.State == "closed" || i.Locked || i.Pinned
+}
+
 // IssueResponse represents a GitHub API response when creating an issue
 type IssueResponse struct {
 	Number int    `json:"number"`
 	URL    string `json:"html_url"`
+	NodeID string `json:"node_id"`
+}
+
+// IssueDetails represents an existing issue as returned by the search API
+type IssueDetails struct {
+	Number    int        `json:"number"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Labels    []Label    `json:"labels"`
+	Assignees []User     `json:"assignees"`
+	Milestone *Milestone `json:"milestone"`
+	URL       string     `json:"html_url"`
+	State     string     `json:"state"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Label represents a repository label
+type Label struct {
+	Name string `json:"name"`
+}
+
+// User represents a GitHub user
+type User struct {
+	Login string `json:"login"`
+}
+
+// IssueActivity represents who created and changed an issue since it was created
+type IssueActivity struct {
+	Number     int
+	State      string
+	Author     string
+	Editors    []string
+	Renamers   []string
+	Commenters []string
+}
+
+// OtherActors returns the distinct users other than the author who edited,
+// renamed or commented on the issue
+func (a *IssueActivity) OtherActors() []string {
+	var actors []string
+	seen := map[string]bool{strings.ToLower(a.Author): true}
+	for _, list := range [][]string{a.Editors, a.Renamers, a.Commenters} {
+		for _, login := range list {
+			if login == "" || seen[strings.ToLower(login)] {
+				continue
+			}
+			seen[strings.ToLower(login)] = true
+			actors = append(actors, login)
+		}
+	}
+	return actors
+}
+
+// DiscussionResponse represents a GitHub API response when creating a discussion
+type DiscussionResponse struct {
+	ID     string `json:"id"`
+	Number int    `json:"number"`
+	URL    string `json:"url"`
+}
+
+// DiscussionCategory represents a discussion category of a repository
+type DiscussionCategory struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+// Project represents a GitHub project (v2) with its fields
+type Project struct {
+	ID     string         `json:"id"`
+	Title  string         `json:"title"`
+	Fields []ProjectField `json:"fields"`
+}
+
+// Field finds a project field by name (case-insensitive)
+func (p *Project) Field(name string) *ProjectField {
+	for i := range p.Fields {
+		if strings.EqualFold(p.Fields[i].Name, name) {
+			return &p.Fields[i]
+		}
+	}
+	return nil
+}
+
+// ProjectField represents a project field. Options holds single select
+// options or iterations.
+type ProjectField struct {
+	ID       string               `json:"id"`
+	Name     string               `json:"name"`
+	DataType string               `json:"dataType"`
+	Options  []ProjectFieldOption `json:"options,omitempty"`
+}
+
+// Option finds a single select option or iteration by name (case-insensitive)
+func (f *ProjectField) Option(name string) *ProjectFieldOption {
+	for i := range f.Options {
+		if strings.EqualFold(f.Options[i].Name, name) {
+			return &f.Options[i]
+		}
+	}
+	return nil
+}
+
+// ProjectFieldOption represents a single select option or an iteration
+type ProjectFieldOption struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// ProjectItemResponse represents a GitHub API response when adding a project item
+type ProjectItemResponse struct {
+	ID string `json:"id"`
+}
+
+// CommentResponse represents a GitHub API response when creating an issue comment
+type CommentResponse struct {
+	ID  int64  `json:"id"`
+	URL string `json:"html_url"`
+}
+
+// Milestone represents a repository milestone
+type Milestone struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	State  string `json:"state,omitempty"`
+}
+
+// Repository represents the repository information needed before creating issues
+type Repository struct {
+	FullName    string                `json:"full_name"`
+	HasIssues   bool                  `json:"has_issues"`
+	Archived    bool                  `json:"archived"`
+	Permissions RepositoryPermissions `json:"permissions"`
+}
+
+// RepositoryPermissions represents the viewer's permissions on a repository
+type RepositoryPermissions struct {
+	Admin    bool `json:"admin"`
+	Maintain bool `json:"maintain"`
+	Push     bool `json:"push"`
+	Triage   bool `json:"triage"`
+	Pull     bool `json:"pull"`
+}
+
+// CanPush reports whether the viewer has push access or higher
+func (p RepositoryPermissions) CanPush() bool {
+	return p.Admin || p.Maintain || p.Push
+}
+
+// CanTriage reports whether the viewer has triage access or higher
+func (p RepositoryPermissions) CanTriage() bool {
+	return p.CanPush() || p.Triage
+}
+
+// Level returns the name of the viewer's highest permission
+func (p RepositoryPermissions) Level() string {
+	switch {
+	case p.Admin:
+		return "admin"
+	case p.Maintain:
+		return "maintain"
+	case p.Push:
+		return "write"
+	case p.Triage:
+		return "triage"
+	case p.Pull:
+		return "read"
+	default:
+		return "no"
+	}
 }
 
 // RateLimit represents GitHub API rate limit information
@@ -52,5 +273,8 @@ type RateLimit struct {
 
 // RateLimitResponse represents GitHub API rate limit response
 type RateLimitResponse struct {
-	Rate RateLimit `json:"rate"`
+	Rate      RateLimit `json:"rate"`
+	Resources struct {
+		GraphQL RateLimit `json:"graphql"`
+	} `json:"resources"`
 }
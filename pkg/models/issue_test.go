@@ -0,0 +1,24 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIssueActivityOtherActors(t *testing.T) {
+	activity := &IssueActivity{
+		Author:     "bulk-bot",
+		Editors:    []string{"bulk-bot", "alice"},
+		Renamers:   []string{"Alice"},
+		Commenters: []string{"BULK-BOT", "bob", ""},
+	}
+
+	expected := []string{"alice", "bob"}
+	if actors := activity.OtherActors(); !reflect.DeepEqual(actors, expected) {
+		t.Errorf("Expected %v, got %v", expected, actors)
+	}
+
+	if actors := (&IssueActivity{Author: "bulk-bot", Commenters: []string{"bulk-bot"}}).OtherActors(); actors != nil {
+		t.Errorf("Expected no other actors, got %v", actors)
+	}
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// RepoRef identifies a repository, optionally on a specific GitHub host
+type RepoRef struct {
+	Host  string
+	Owner string
+	Name  string
+}
+
+// ParseRepoRef parses a repository reference in OWNER/REPO or HOST/OWNER/REPO form
+func ParseRepoRef(ref string) (RepoRef, error) {
+	parts := strings.Split(strings.TrimSpace(ref), "/")
+	for _, part := range parts {
+		if part == "" {
+			return RepoRef{}, fmt.Errorf("'%s' is not in OWNER/REPO or HOST/OWNER/REPO format", ref)
+		}
+	}
+
+	switch len(parts) {
+	case 2:
+		return RepoRef{Owner: parts[0], Name: parts[1]}, nil
+	case 3:
+		return RepoRef{Host: parts[0], Owner: parts[1], Name: parts[2]}, nil
+	default:
+		return RepoRef{}, fmt.Errorf("'%s' is not in OWNER/REPO or HOST/OWNER/REPO format", ref)
+	}
+}
+
+// FullName returns the repository in OWNER/REPO form, without the host
+func (r RepoRef) FullName() string {
+	return r.Owner + "/" + r.Name
+}
+
+// String returns the repository in OWNER/REPO form, prefixed with the host if set
+func (r RepoRef) String() string {
+	if r.Host == "" {
+		return r.FullName()
+	}
+	return r.Host + "/" + r.FullName()
+}
+
+// ParseIssueURL parses the web URL of an issue, such as
+// https://github.com/OWNER/REPO/issues/12, into its repository (with the
+// host) and number
+func ParseIssueURL(issueURL string) (RepoRef, int, error) {
+	invalid := fmt.Errorf("'%s' is not the URL of an issue", issueURL)
+	u, err := url.Parse(strings.TrimSpace(issueURL))
+	if err != nil || u.Host == "" {
+		return RepoRef{}, 0, invalid
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) != 4 || parts[0] == "" || parts[1] == "" || parts[2] != "issues" {
+		return RepoRef{}, 0, invalid
+	}
+	number, err := strconv.Atoi(parts[3])
+	if err != nil || number <= 0 {
+		return RepoRef{}, 0, invalid
+	}
+	return RepoRef{Host: u.Host, Owner: parts[0], Name: parts[1]}, number, nil
+}
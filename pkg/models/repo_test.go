@@ -0,0 +1,81 @@
+package models
+
+import "testing"
+
+func TestParseRepoRef(t *testing.T) {
+	testCases := []struct {
+		ref           string
+		expected      RepoRef
+		expectedError bool
+	}{
+		{ref: "octo/api", expected: RepoRef{Owner: "octo", Name: "api"}},
+		{ref: "ghe.example.com/octo/api", expected: RepoRef{Host: "ghe.example.com", Owner: "octo", Name: "api"}},
+		{ref: " octo/api ", expected: RepoRef{Owner: "octo", Name: "api"}},
+		{ref: "api", expectedError: true},
+		{ref: "octo/", expectedError: true},
+		{ref: "a/b/c/d", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.ref, func(t *testing.T) {
+			ref, err := ParseRepoRef(tc.ref)
+
+			if tc.expectedError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if ref != tc.expected {
+				t.Errorf("Expected %+v, got %+v", tc.expected, ref)
+			}
+		})
+	}
+}
+
+func TestRepoRefString(t *testing.T) {
+	if s := (RepoRef{Owner: "octo", Name: "api"}).String(); s != "octo/api" {
+		t.Errorf("Expected 'octo/api', got '%s'", s)
+	}
+	if s := (RepoRef{Host: "ghe.example.com", Owner: "octo", Name: "api"}).String(); s != "ghe.example.com/octo/api" {
+		t.Errorf("Expected 'ghe.example.com/octo/api', got '%s'", s)
+	}
+}
+
+func TestParseIssueURL(t *testing.T) {
+	testCases := []struct {
+		url            string
+		expected       RepoRef
+		expectedNumber int
+		expectedError  bool
+	}{
+		{url: "https://github.com/octo/api/issues/12", expected: RepoRef{Host: "github.com", Owner: "octo", Name: "api"}, expectedNumber: 12},
+		{url: "https://ghe.example.com/octo/api/issues/3/", expected: RepoRef{Host: "ghe.example.com", Owner: "octo", Name: "api"}, expectedNumber: 3},
+		{url: "https://github.com/octo/api/pull/12", expectedError: true},
+		{url: "https://github.com/octo/api/issues/new", expectedError: true},
+		{url: "octo/api#12", expectedError: true},
+		{url: "", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.url, func(t *testing.T) {
+			ref, number, err := ParseIssueURL(tc.url)
+
+			if tc.expectedError {
+				if err == nil {
+					t.Error("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got: %v", err)
+			}
+			if ref != tc.expected || number != tc.expectedNumber {
+				t.Errorf("Expected %+v #%d, got %+v #%d", tc.expected, tc.expectedNumber, ref, number)
+			}
+		})
+	}
+}